@@ -2,8 +2,10 @@ package log_v1
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -33,3 +35,265 @@ func (e ErrOffsetOutOfRange) GRPCStatus() *status.Status {
 func (e ErrOffsetOutOfRange) Error() string {
 	return e.GRPCStatus().Err().Error()
 }
+
+// Error returned when a record's bytes on disk don't match its checksum
+// The record is never handed back to the client since its contents can't be trusted
+type ErrCorruptRecord struct {
+	Offset uint64
+}
+
+// Return a gRPC status for the client
+func (e ErrCorruptRecord) GRPCStatus() *status.Status {
+	st := status.New(codes.DataLoss, fmt.Sprintf("corrupt record: %d", e.Offset))
+	msg := fmt.Sprintf(
+		"The record at offset %d failed its integrity check",
+		e.Offset,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrCorruptRecord) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when a request names a topic the cluster doesn't have
+type ErrTopicNotFound struct {
+	Topic string
+}
+
+// Return a gRPC status for the client
+func (e ErrTopicNotFound) GRPCStatus() *status.Status {
+	st := status.New(codes.NotFound, fmt.Sprintf("topic not found: %s", e.Topic))
+	msg := fmt.Sprintf(
+		"The topic %q doesn't exist, create it first",
+		e.Topic,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrTopicNotFound) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when creating a topic that already exists
+type ErrTopicExists struct {
+	Topic string
+}
+
+// Return a gRPC status for the client
+func (e ErrTopicExists) GRPCStatus() *status.Status {
+	st := status.New(codes.AlreadyExists, fmt.Sprintf("topic already exists: %s", e.Topic))
+	msg := fmt.Sprintf(
+		"The topic %q already exists",
+		e.Topic,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrTopicExists) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when a request names a partition past the last one
+type ErrPartitionNotFound struct {
+	Partition  uint32
+	Partitions uint32
+}
+
+// Return a gRPC status for the client
+func (e ErrPartitionNotFound) GRPCStatus() *status.Status {
+	st := status.New(codes.NotFound, fmt.Sprintf("partition not found: %d", e.Partition))
+	msg := fmt.Sprintf(
+		"The partition %d doesn't exist, topics have %d partitions numbered from 0",
+		e.Partition,
+		e.Partitions,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrPartitionNotFound) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when a request needs the partition's leader and reaches another server
+type ErrNotLeader struct {
+	// RPC address of the partition's leader, empty if there isn't one right now
+	Leader string
+}
+
+// Return a gRPC status for the client, with the leader's address in an ErrorInfo detail so clients can retry there
+func (e ErrNotLeader) GRPCStatus() *status.Status {
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("not the leader, the leader is %q", e.Leader))
+	msg := "This server isn't the partition's leader, send the request to the leader"
+	if e.Leader == "" {
+		msg = "This server isn't the partition's leader and there's no leader right now, retry once one's elected"
+	}
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   "NOT_LEADER",
+		Domain:   "ledger",
+		Metadata: map[string]string{"leader": e.Leader},
+	}
+	std, err := st.WithDetails(d, info)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrNotLeader) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when a producer's sequence number skips past the one after its last record, so a record in between
+// is missing
+type ErrOutOfOrderSequence struct {
+	ProducerId string
+	Sequence   uint64
+	Expected   uint64
+}
+
+// Return a gRPC status for the client
+func (e ErrOutOfOrderSequence) GRPCStatus() *status.Status {
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("out of order sequence: %d", e.Sequence))
+	msg := fmt.Sprintf(
+		"Producer %q sent sequence number %d, but its next record for this topic and partition must have %d",
+		e.ProducerId,
+		e.Sequence,
+		e.Expected,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrOutOfOrderSequence) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when a producer retries a record it appended too long ago for its offset to be remembered
+// The record was appended, and isn't appended again
+type ErrDuplicateSequence struct {
+	ProducerId string
+	Sequence   uint64
+}
+
+// Return a gRPC status for the client
+func (e ErrDuplicateSequence) GRPCStatus() *status.Status {
+	st := status.New(codes.AlreadyExists, fmt.Sprintf("duplicate sequence: %d", e.Sequence))
+	msg := fmt.Sprintf(
+		"Producer %q already appended the record with sequence number %d",
+		e.ProducerId,
+		e.Sequence,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrDuplicateSequence) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when fetching the offset of a consumer group that hasn't committed one
+type ErrOffsetNotCommitted struct {
+	Group string
+	Topic string
+}
+
+// Return a gRPC status for the client
+func (e ErrOffsetNotCommitted) GRPCStatus() *status.Status {
+	st := status.New(codes.NotFound, fmt.Sprintf("offset not committed: %s", e.Group))
+	msg := fmt.Sprintf(
+		"The consumer group %q hasn't committed an offset for the topic %q",
+		e.Group,
+		e.Topic,
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrOffsetNotCommitted) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Error returned when removing an ACL rule that wasn't added through the policy admin service
+type ErrRuleNotFound struct {
+	Rule []string
+}
+
+// Return a gRPC status for the client
+func (e ErrRuleNotFound) GRPCStatus() *status.Status {
+	st := status.New(codes.NotFound, fmt.Sprintf("rule not found: %s", strings.Join(e.Rule, ", ")))
+	msg := fmt.Sprintf(
+		"The rule %q wasn't added through the policy admin service, rules in the policy file can only be removed there",
+		strings.Join(e.Rule, ", "),
+	)
+	d := &errdetails.LocalizedMessage{
+		Locale:  "en-US",
+		Message: msg,
+	}
+	std, err := st.WithDetails(d)
+	if err != nil {
+		return st
+	}
+	return std
+}
+
+func (e ErrRuleNotFound) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
@@ -9,11 +9,13 @@ import (
 	"os/signal"
 	"path"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"ledger/internal/agent"
+	ledgerlog "ledger/internal/log"
 	"ledger/internal/web"
 )
 
@@ -33,6 +35,8 @@ func main() {
 		log.Fatal(err)
 	}
 
+	cmd.AddCommand(verifyCommand())
+
 	err = cmd.Execute()
 	if err != nil {
 		log.Fatal(err)
@@ -44,6 +48,14 @@ type cli struct {
 	cfg cfg
 }
 
+// A topic's settings in the config file, named like the flags they replace
+type topicConfig struct {
+	RetentionMaxBytes uint64        `mapstructure:"retention-max-bytes"`
+	RetentionMaxAge   time.Duration `mapstructure:"retention-max-age"`
+	Compaction        bool          `mapstructure:"compaction"`
+	ArchiveLocalBytes uint64        `mapstructure:"archive-local-bytes"`
+}
+
 // Reads the config fields from flags or a file and setups the agent's config
 func (c *cli) setupConfig(cmd *cobra.Command, args []string) error {
 	var err error
@@ -75,8 +87,44 @@ func (c *cli) setupConfig(cmd *cobra.Command, args []string) error {
 	config.RPCPort = viper.GetInt("rpc-port")
 	config.StartJoinAddrs = viper.GetStringSlice("start-join-addrs")
 	config.Bootstrap = viper.GetBool("bootstrap")
+	config.Partitions = viper.GetUint32("partitions")
 	config.ACLModelFile = viper.GetString("acl-model-file")
 	config.ACLPolicyFile = viper.GetString("acl-policy-file")
+	config.ACLReloadInterval = viper.GetDuration("acl-reload-interval")
+	config.PolicyAdmin = viper.GetBool("policy-admin")
+
+	if config.LogCodec, err = ledgerlog.ParseCodec(viper.GetString("log-compression")); err != nil {
+		return err
+	}
+	if config.LogSync, err = ledgerlog.ParseSyncPolicy(viper.GetString("log-sync")); err != nil {
+		return err
+	}
+	config.LogSyncEveryRecords = viper.GetUint64("log-sync-every-records")
+	config.LogSyncInterval = viper.GetDuration("log-sync-interval")
+	config.EncryptionKeyFile = viper.GetString("encryption-key-file")
+	config.EncryptionKeyID = viper.GetString("encryption-key-id")
+	config.RetentionMaxBytes = viper.GetUint64("retention-max-bytes")
+	config.RetentionMaxAge = viper.GetDuration("retention-max-age")
+	config.RetentionMinOffset = viper.GetUint64("retention-min-offset")
+	config.Compaction = viper.GetBool("compaction")
+	config.CompactionInterval = viper.GetDuration("compaction-interval")
+	config.ArchiveDir = viper.GetString("archive-dir")
+	config.ArchiveLocalBytes = viper.GetUint64("archive-local-bytes")
+	// only the config file can set topics, as a map of topic names to the settings they replace
+	var topics map[string]topicConfig
+	if err = viper.UnmarshalKey("topics", &topics); err != nil {
+		return err
+	}
+	for name, t := range topics {
+		if config.Topics == nil {
+			config.Topics = make(map[string]ledgerlog.TopicConfig)
+		}
+		config.Topics[name] = ledgerlog.TopicConfig(t)
+	}
+	config.HashChain = viper.GetBool("hash-chain")
+	config.ChainSigningKeyFile = viper.GetString("chain-signing-key-file")
+	config.ChainVerifyKeysFile = viper.GetString("chain-verify-keys-file")
+	config.CheckpointInterval = viper.GetDuration("checkpoint-interval")
 
 	config.ServerTLSConfig.CAFile = viper.GetString("server-tls-ca-file")
 	config.ServerTLSConfig.CertFile = viper.GetString("server-tls-cert-file")
@@ -142,8 +190,28 @@ func setupFlags(cmd *cobra.Command) error {
 	fs.String("bind-addr", fmt.Sprintf("127.0.0.1:%d", serfPort), "Server address for Serf")
 	fs.StringSlice("start-join-addrs", nil, "Serf address to join")
 	fs.Bool("bootstrap", false, "Bootstrap the cluster")
+	fs.Uint32("partitions", 1, "Partitions every topic is split into, each with a Raft leader of its own, the same on every node")
 	fs.String("acl-model-file", "", "Path to ACL model")
 	fs.String("acl-policy-file", "", "Path to ACL policy")
+	fs.Duration("acl-reload-interval", time.Second, "How often to check the ACL policy file for changes, 0 never reloads it")
+	fs.Bool("policy-admin", false, "Serve the PolicyAdmin service, to manage ACL rules replicated to every node")
+	fs.String("log-compression", "none", "Compression for each appended batch of records: none, gzip or snappy")
+	fs.String("log-sync", "none", "When appended records are fsynced: none, always or batch")
+	fs.Uint64("log-sync-every-records", 0, "With batch syncing, fsync once this many records are waiting")
+	fs.Duration("log-sync-interval", 0, "With batch syncing, fsync waiting records at least this often")
+	fs.String("encryption-key-file", "", "Path to the keys the log is encrypted with, falls back to $"+agent.EncryptionKeysEnv)
+	fs.String("encryption-key-id", "", "ID of the key new records are encrypted with, defaults to the last key")
+	fs.Uint64("retention-max-bytes", 0, "Delete the oldest log segments once the log is bigger than this, 0 for no limit")
+	fs.Duration("retention-max-age", 0, "Delete log segments that haven't been written to for this long, 0 for no limit")
+	fs.Uint64("retention-min-offset", 0, "Never delete log segments holding this offset or later ones")
+	fs.Bool("compaction", false, "Compact closed log segments so only the latest record for each key remains")
+	fs.Duration("compaction-interval", 10*time.Minute, "How often to compact closed log segments")
+	fs.String("archive-dir", "", "Directory to upload closed log segments to, under a directory per node, empty to keep them on local disk only")
+	fs.Uint64("archive-local-bytes", 0, "Delete uploaded log segments from local disk once the local log is bigger than this, 0 to keep them")
+	fs.Bool("hash-chain", false, "Chain each record to the one before it by hash, so changes to the log can be detected")
+	fs.String("chain-signing-key-file", "", "Path to the key this node signs checkpoints with while it's the leader")
+	fs.String("chain-verify-keys-file", "", "Path to the public keys checkpoints are verified with")
+	fs.Duration("checkpoint-interval", time.Minute, "How often the leader writes a signed checkpoint into the log")
 	fs.String("server-tls-cert-file", "", "Path to server tls cert")
 	fs.String("server-tls-key-file", "", "Path to server tls key")
 	fs.String("server-tls-ca-file", "", "Path to server certificate authority")
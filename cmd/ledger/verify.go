@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"ledger/internal/agent"
+	ledgerlog "ledger/internal/log"
+)
+
+// Recomputes a topic's hash chain from the data directory and reports the first offset where it breaks
+//
+// The log's opened read-only, so nothing in the data directory is changed, but the node using it should be stopped
+// first so the log isn't changing underneath the check
+func verifyCommand() *cobra.Command {
+	hostname, _ := os.Hostname()
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a topic's hash chain and signed checkpoints",
+		Args:  cobra.NoArgs,
+		RunE:  runVerify,
+	}
+	fs := cmd.Flags()
+	fs.String("data-dir", path.Join(os.TempDir(), "ledger"), "Directory the log and Raft data are stored in")
+	fs.String("topic", ledgerlog.DefaultTopic, "Topic to verify")
+	fs.Uint32("partition", 0, "Partition of the topic to verify")
+	fs.Uint64("from", 0, "First offset to verify")
+	fs.Uint64("to", math.MaxUint64, "Offset to stop verifying at, exclusive")
+	fs.String("chain-verify-keys-file", "", "Path to the public keys checkpoints are verified with")
+	fs.String("encryption-key-file", "", "Path to the keys the log is encrypted with, falls back to $"+agent.EncryptionKeysEnv)
+	fs.String("archive-dir", "", "Directory closed log segments were uploaded to, the same as the server's")
+	fs.Bool("compaction", false, "Set if the server compacts the topic, so the gaps compaction leaves don't break the chain")
+	fs.String("node-name", hostname, "Name of the server the data directory belongs to, whose segments are archived under it")
+	return cmd
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	fs := cmd.Flags()
+	dataDir, _ := fs.GetString("data-dir")
+	topic, _ := fs.GetString("topic")
+	partition, _ := fs.GetUint32("partition")
+	from, _ := fs.GetUint64("from")
+	to, _ := fs.GetUint64("to")
+	keysFile, _ := fs.GetString("chain-verify-keys-file")
+	encryptionKeyFile, _ := fs.GetString("encryption-key-file")
+	archiveDir, _ := fs.GetString("archive-dir")
+	nodeName, _ := fs.GetString("node-name")
+	compaction, _ := fs.GetBool("compaction")
+
+	c := ledgerlog.Config{}
+	c.Chain.Enabled = true
+	// the log's opened read-only, so this only lets Verify skip over the records compaction removed
+	c.Compaction.Enabled = compaction
+	if keysFile != "" {
+		keys, err := ledgerlog.LoadVerifyKeys(keysFile)
+		if err != nil {
+			return err
+		}
+		c.Chain.VerifyKeys = keys
+	}
+	if encryptionKeyFile != "" || os.Getenv(agent.EncryptionKeysEnv) != "" {
+		keyring, err := ledgerlog.LoadKeyring(encryptionKeyFile, agent.EncryptionKeysEnv, "")
+		if err != nil {
+			return err
+		}
+		c.Encryption.Keyring = keyring
+	}
+	if archiveDir != "" {
+		archiveDir = path.Join(archiveDir, nodeName)
+	}
+	if partition != 0 {
+		// the first partition's at the root, the others are under partitions/<partition>, in the archive too
+		p := fmt.Sprint(partition)
+		dataDir = path.Join(dataDir, "partitions", p)
+		if archiveDir != "" {
+			archiveDir = path.Join(archiveDir, "partitions", p)
+		}
+	}
+	if archiveDir != "" {
+		// the default topic's segments are archived at the root, every other topic's under its name
+		if topic != ledgerlog.DefaultTopic {
+			archiveDir = path.Join(archiveDir, topic)
+		}
+		archive, err := ledgerlog.NewDirArchive(archiveDir)
+		if err != nil {
+			return err
+		}
+		c.Tiering.Archive = archive
+	}
+
+	dir := path.Join(dataDir, "log", topic)
+	if _, err := os.Stat(dir); os.IsNotExist(err) && topic == ledgerlog.DefaultTopic {
+		// written before there were topics and not started since
+		dir = path.Join(dataDir, "log")
+	} else if err != nil {
+		return fmt.Errorf("topic %s: %v", topic, err)
+	}
+	l, err := ledgerlog.OpenReadOnly(dir, c)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	if r := l.Recovery(); r.DiscardedBytes > 0 {
+		// left for the node to discard when it next starts, so it's reported rather than repaired
+		fmt.Printf(
+			"segment %d ends with %d bytes of a record that was cut off, which the node discards when it next starts\n",
+			r.BaseOffset,
+			r.DiscardedBytes,
+		)
+	}
+	v, err := l.Verify(from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"verified %d records and %d checkpoints, with %d gaps left by compaction\n",
+		v.Records,
+		v.Checkpoints,
+		v.Gaps,
+	)
+	return nil
+}
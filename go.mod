@@ -6,8 +6,10 @@ require (
 	github.com/casbin/casbin v1.9.1
 	github.com/gogo/protobuf v1.3.1
 	github.com/golang/protobuf v1.4.2
+	github.com/golang/snappy v0.0.1
 	github.com/google/uuid v1.1.1
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.0
+	github.com/hashicorp/go-hclog v0.9.1
 	github.com/hashicorp/raft v1.1.1
 	github.com/hashicorp/raft-boltdb v0.0.0-20171010151810-6e5ba93211ea
 	github.com/hashicorp/serf v0.9.2
@@ -26,6 +28,6 @@ require (
 	golang.org/x/crypto v0.0.0-20191205180655-e7c4368fe9dd // indirect
 	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.29.1
-	google.golang.org/protobuf v1.25.0 // indirect
+	google.golang.org/protobuf v1.25.0
 	launchpad.net/gocheck v0.0.0-20140225173054-000000000087 // indirect
 )
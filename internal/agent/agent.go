@@ -1,14 +1,16 @@
 package agent
 
 import (
-	"bytes"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/raft"
 	"github.com/soheilhy/cmux"
 	"google.golang.org/grpc"
@@ -21,6 +23,9 @@ import (
 )
 
 func New(config Config) (*Agent, error) {
+	if config.Logger == nil {
+		config.Logger = hclog.New(&hclog.LoggerOptions{Name: "ledger"})
+	}
 	a := &Agent{
 		Config:    config,
 		shutdowns: make(chan struct{}),
@@ -28,6 +33,7 @@ func New(config Config) (*Agent, error) {
 	setup := []func() error{
 		// order matters here
 		a.setupMux,
+		a.setupAuthorizer,
 		a.setupLog,
 		a.setupServer,
 		a.setupMembership,
@@ -52,10 +58,14 @@ type Agent struct {
 	// multiplexer to service different services on the same port
 	// e.g. on the same port we can serve our log server with our Raft servers
 	mux cmux.CMux
-	// distributed log service
-	log *log.DistributedLog
+	// enforces the ACL policy file along with the rules replicated through the log
+	authorizer *auth.Authorizer
+	// distributed log service, a Raft group per partition
+	log *log.PartitionedLog
 	// server for our log service that clients can make requests to
 	server *grpc.Server
+	// forwards writes that reach this server to the partition's leader
+	forwarder *web.Forwarder
 	// service discovery
 	membership *membership.Membership
 
@@ -76,21 +86,29 @@ func (a *Agent) serve() error {
 	return nil
 }
 
+func (a *Agent) setupAuthorizer() error {
+	a.authorizer = auth.New(
+		a.Config.ACLModelFile,
+		a.Config.ACLPolicyFile,
+	)
+	if a.Config.ACLReloadInterval > 0 {
+		a.authorizer.Watch(a.Config.ACLReloadInterval, a.Config.Logger)
+	}
+	return nil
+}
+
 func (a *Agent) setupLog() error {
 	raftLn := a.mux.Match(func(reader io.Reader) bool {
-		// read one byte to identify the raft connection
+		// read one byte to identify the raft connection, of any partition
 		b := make([]byte, 1)
 		_, err := reader.Read(b)
 		if err != nil {
 			return false
 		}
-		return bytes.Compare(
-			b,
-			[]byte{byte(log.RaftRPC)},
-		) == 0
+		return b[0] == byte(log.RaftRPC) || b[0] == byte(log.RaftPartitionRPC)
 	})
 
-	logConfig := log.Config{}
+	logConfig := log.Config{Logger: a.Config.Logger}
 	logConfig.Raft.StreamLayer = log.NewStreamLayer(
 		raftLn,
 		a.Config.ServerTLSConfig,
@@ -98,9 +116,57 @@ func (a *Agent) setupLog() error {
 	)
 	logConfig.Raft.LocalID = raft.ServerID(a.Config.NodeName)
 	logConfig.Raft.Bootstrap = a.Config.Bootstrap
+	logConfig.Raft.Partitions = a.Config.Partitions
+	// every node enforces the rules added through the policy admin service, whether it serves it or not
+	logConfig.Raft.Policy = log.NewPolicy(a.authorizer.CheckRule, a.authorizer.SetRules)
+	logConfig.Segment.Codec = a.Config.LogCodec
+	logConfig.Segment.Sync = a.Config.LogSync
+	logConfig.Segment.SyncEveryRecords = a.Config.LogSyncEveryRecords
+	logConfig.Segment.SyncInterval = a.Config.LogSyncInterval
+	if a.Config.EncryptionKeyFile != "" || os.Getenv(EncryptionKeysEnv) != "" {
+		keyring, err := log.LoadKeyring(a.Config.EncryptionKeyFile, EncryptionKeysEnv, a.Config.EncryptionKeyID)
+		if err != nil {
+			return err
+		}
+		logConfig.Encryption.Keyring = keyring
+	}
+	logConfig.Retention.MaxBytes = a.Config.RetentionMaxBytes
+	logConfig.Retention.MaxAge = a.Config.RetentionMaxAge
+	logConfig.Retention.MinOffset = a.Config.RetentionMinOffset
+	logConfig.Compaction.Enabled = a.Config.Compaction
+	logConfig.Compaction.Interval = a.Config.CompactionInterval
+	logConfig.Topics = a.Config.Topics
+	if a.Config.HashChain {
+		logConfig.Chain.Enabled = true
+		logConfig.Chain.CheckpointInterval = a.Config.CheckpointInterval
+		if a.Config.ChainSigningKeyFile != "" {
+			id, key, err := log.LoadSigningKey(a.Config.ChainSigningKeyFile)
+			if err != nil {
+				return err
+			}
+			logConfig.Chain.SigningKeyID = id
+			logConfig.Chain.SigningKey = key
+		}
+		if a.Config.ChainVerifyKeysFile != "" {
+			keys, err := log.LoadVerifyKeys(a.Config.ChainVerifyKeysFile)
+			if err != nil {
+				return err
+			}
+			logConfig.Chain.VerifyKeys = keys
+		}
+	}
+	if a.Config.ArchiveDir != "" {
+		// servers keep their own copies of each partition, so each one archives its segments under its own name
+		archive, err := log.NewDirArchive(filepath.Join(a.Config.ArchiveDir, a.Config.NodeName))
+		if err != nil {
+			return err
+		}
+		logConfig.Tiering.Archive = archive
+		logConfig.Tiering.LocalBytes = a.Config.ArchiveLocalBytes
+	}
 
 	var err error
-	a.log, err = log.NewDistributedLog(
+	a.log, err = log.NewPartitionedLog(
 		a.Config.DataDir,
 		logConfig,
 	)
@@ -116,13 +182,20 @@ func (a *Agent) setupLog() error {
 }
 
 func (a *Agent) setupServer() error {
+	// servers connect to each other as peers, like Raft does
+	forwardOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if a.Config.PeerTLSConfig != nil {
+		forwardOpts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(a.Config.PeerTLSConfig))}
+	}
+	a.forwarder = web.NewForwarder(forwardOpts...)
 	serverConfig := &web.Config{
-		CommitLog: a.log,
-		Authorizer: auth.New(
-			a.Config.ACLModelFile,
-			a.Config.ACLPolicyFile,
-		),
+		CommitLog:    a.log,
+		Authorizer:   a.authorizer,
 		ServerGetter: a.log,
+		Forwarder:    a.forwarder,
+	}
+	if a.Config.PolicyAdmin {
+		serverConfig.Policy = policyStore{a.log, a.authorizer}
 	}
 
 	var opts []grpc.ServerOption
@@ -172,6 +245,9 @@ type Config struct {
 	PeerTLSConfig *tls.Config
 	// directory that will store our logs
 	DataDir string
+	// where errors from the log's background work and from reloading the ACL policy file are reported, defaults to a
+	// logger named ledger writing to stderr
+	Logger hclog.Logger
 	// BindAddr's
 	// - IP is the base address for both RPC and Serf
 	// - Port is used by Serf
@@ -187,13 +263,75 @@ type Config struct {
 	// In a production, specify atleast 3 address to avoid 1-2 node failures
 	StartJoinAddrs []string
 	// authorization config files
+	// the subject on PeerTLSConfig's certificate needs forward on the cluster for the other servers to accept writes
+	// this server forwards to them as coming from the client that sent them
 	ACLModelFile  string
 	ACLPolicyFile string
+	// how often the policy file is checked for changes, which are loaded without a restart, 0 never checks
+	ACLReloadInterval time.Duration
+	// serve the PolicyAdmin service, to list, add and remove ACL rules replicated to every node
+	PolicyAdmin bool
 	// Indicate this server to bootstrap the cluster
 	// Should be set to true when starting the first node of the cluster to elect it as the leader
 	Bootstrap bool
+	// partitions every topic is split into, each a Raft group with a leader of its own, defaults to 1
+	// every node in the cluster must use the same number
+	Partitions uint32
+	// compression for records appended to the log, applied to each produced batch as a whole
+	LogCodec log.Codec
+	// when appended records are fsynced, records are only acknowledged once they meet this policy
+	LogSync log.SyncPolicy
+	// with log.SyncBatch, fsync once this many records are waiting or LogSyncInterval passes
+	LogSyncEveryRecords uint64
+	LogSyncInterval     time.Duration
+	// file holding the keys the log is encrypted with at rest, one "<id>:<base64 key>" per line
+	// falls back to the EncryptionKeysEnv environment variable, and the log isn't encrypted if neither is set
+	EncryptionKeyFile string
+	// ID of the key new records are encrypted with, defaults to the last key
+	EncryptionKeyID string
+	// retention rules for the transaction log, old segments past any limit are deleted in the background
+	// zero values mean no limit
+	RetentionMaxBytes  uint64
+	RetentionMaxAge    time.Duration
+	RetentionMinOffset uint64
+	// compact closed segments in the background so only the latest record for each key remains
+	Compaction bool
+	// how often to compact, defaults to ten minutes
+	CompactionInterval time.Duration
+	// retention, compaction and ArchiveLocalBytes for single topics by name, in place of the settings above
+	// every node in the cluster must use the same ones
+	Topics map[string]log.TopicConfig
+	// directory closed log segments are uploaded to, under a directory named after the node so servers can share it,
+	// empty keeps every segment on local disk only
+	ArchiveDir string
+	// once uploaded, delete the oldest segments from local disk while the local log is bigger than this, they're
+	// fetched back from ArchiveDir when read, 0 keeps them on local disk too
+	ArchiveLocalBytes uint64
+	// each record carries the hash of the one before it, so changes to the log can be detected
+	HashChain bool
+	// file holding the "<id>:<base64 ed25519 key>" key this node signs checkpoints with while it's the leader,
+	// empty means it doesn't sign any
+	ChainSigningKeyFile string
+	// file holding the public keys checkpoints are verified with, one "<id>:<base64 key>" per line
+	ChainVerifyKeysFile string
+	// how often the leader writes a signed checkpoint into the log
+	CheckpointInterval time.Duration
+}
+
+// The policy admin service's rules are replicated through the log, the policy file's are read by the authorizer
+type policyStore struct {
+	*log.PartitionedLog
+	authorizer *auth.Authorizer
 }
 
+func (s policyStore) FileRules() [][]string {
+	return s.authorizer.FileRules()
+}
+
+// Environment variable holding the log's encryption keys when there's no key file, as comma-separated
+// "<id>:<base64 key>" entries
+const EncryptionKeysEnv = "LEDGER_ENCRYPTION_KEYS"
+
 // Returns the full gRPC address, e.g. "127.0.0.1:8080"
 func (this *Config) RPCAddr() string {
 	return fmt.Sprintf("%s:%d", this.BindAddr.IP.String(), this.RPCPort)
@@ -218,7 +356,9 @@ func (a *Agent) Shutdown() error {
 	shutdown := []func() error{
 		a.membership.Leave,
 		serverCloseFn,
+		a.forwarder.Close,
 		a.log.Close,
+		a.authorizer.Close,
 	}
 	for _, fn := range shutdown {
 		err := fn()
@@ -2,24 +2,51 @@ package auth
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/model"
+	fileadapter "github.com/casbin/casbin/persist/file-adapter"
+	"github.com/hashicorp/go-hclog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 // Accepts ACL model and policy files
+// Like casbin.NewEnforcer, it panics if they can't be loaded
 func New(model, policy string) *Authorizer {
-	enforcer := casbin.NewEnforcer(model, policy)
-	return &Authorizer{enforcer}
+	a := &Authorizer{model: model, policy: policy}
+	if err := a.Reload(); err != nil {
+		panic(err)
+	}
+	return a
 }
 
 type Authorizer struct {
+	model  string
+	policy string
+
+	mu       sync.RWMutex
 	enforcer *casbin.Enforcer
+	// the policy file's rules as of the last reload, and the rules added on top of them through Raft
+	// each rule is led by its type, p for a policy rule or g for a role, like a line of the policy file
+	fileRules [][]string
+	rules     [][]string
+
+	// closed to stop watching the policy file
+	stop chan struct{}
+	done chan struct{}
 }
 
 func (this *Authorizer) Authorize(subject, object, action string) error {
-	if !this.enforcer.Enforce(subject, object, action) {
+	this.mu.RLock()
+	enforcer := this.enforcer
+	this.mu.RUnlock()
+	if !enforcer.Enforce(subject, object, action) {
 		msg := fmt.Sprintf(
 			"%s not permitted to %s to %s",
 			subject,
@@ -32,3 +59,159 @@ func (this *Authorizer) Authorize(subject, object, action string) error {
 
 	return nil
 }
+
+// Loads the policy file again
+// The new rules are swapped in all at once, so no request sees a partly loaded policy, and the old rules are kept if
+// the file can't be loaded
+func (this *Authorizer) Reload() error {
+	fileRules, err := readPolicy(this.model, this.policy)
+	if err != nil {
+		return err
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	enforcer, err := newEnforcer(this.model, fileRules, this.rules)
+	if err != nil {
+		return err
+	}
+	this.enforcer, this.fileRules = enforcer, fileRules
+	return nil
+}
+
+// Replaces the rules enforced on top of the policy file's, keeping the old rules if any of the new ones is invalid
+func (this *Authorizer) SetRules(rules [][]string) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	enforcer, err := newEnforcer(this.model, this.fileRules, rules)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	this.enforcer, this.rules = enforcer, rules
+	return nil
+}
+
+// Returns an error if the model has no place for the rule, the error SetRules would give for it
+func (this *Authorizer) CheckRule(rule []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("auth: loading %s: %v", this.model, r)
+		}
+	}()
+	if err = checkRule(casbin.NewModel(this.model, ""), rule); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// Returns the policy file's rules as of the last reload
+func (this *Authorizer) FileRules() [][]string {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	return this.fileRules
+}
+
+// Reloads the policy file whenever it changes, checking every interval until Close is called
+// A file that can't be loaded is reported through the logger, and the last good policy stays in place
+func (this *Authorizer) Watch(interval time.Duration, logger hclog.Logger) {
+	this.stop = make(chan struct{})
+	this.done = make(chan struct{})
+	last, _ := os.Stat(this.policy)
+	go func() {
+		defer close(this.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.stop:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(this.policy)
+				if err != nil || last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+					continue
+				}
+				last = info
+				if err = this.Reload(); err != nil {
+					logger.Error("reloading ACL policy", "file", this.policy, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stops watching the policy file
+func (this *Authorizer) Close() error {
+	if this.stop != nil {
+		close(this.stop)
+		<-this.done
+		this.stop = nil
+	}
+	return nil
+}
+
+// Reads the rules in the policy file
+func readPolicy(model, policy string) (rules [][]string, err error) {
+	// casbin panics on files it can't parse
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("auth: reading %s: %v", policy, r)
+		}
+	}()
+	m := casbin.NewModel(model, "")
+	if err = fileadapter.NewAdapter(policy).LoadPolicy(m); err != nil {
+		return nil, err
+	}
+	for _, sec := range []string{"p", "g"} {
+		var ptypes []string
+		for ptype := range m[sec] {
+			ptypes = append(ptypes, ptype)
+		}
+		sort.Strings(ptypes)
+		for _, ptype := range ptypes {
+			for _, rule := range m[sec][ptype].Policy {
+				rules = append(rules, append([]string{ptype}, rule...))
+			}
+		}
+	}
+	return rules, nil
+}
+
+// Returns an enforcer for the model enforcing the rules
+func newEnforcer(model string, rules ...[][]string) (e *casbin.Enforcer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("auth: loading %s: %v", model, r)
+		}
+	}()
+	m := casbin.NewModel(model, "")
+	e = casbin.NewEnforcer(m)
+	for _, set := range rules {
+		for _, rule := range set {
+			if err := checkRule(m, rule); err != nil {
+				return nil, err
+			}
+			if rule[0][:1] == "g" {
+				e.AddNamedGroupingPolicy(rule[0], rule[1:])
+			} else {
+				e.AddNamedPolicy(rule[0], rule[1:])
+			}
+		}
+	}
+	return e, nil
+}
+
+// Returns an error unless the model has rules of the rule's type, with as many values as it has
+func checkRule(m model.Model, rule []string) error {
+	if len(rule) == 0 || rule[0] == "" || m[rule[0][:1]][rule[0]] == nil {
+		return fmt.Errorf("auth: invalid rule %q, the model has no rules of its type", rule)
+	}
+	sec, ast := rule[0][:1], m[rule[0][:1]][rule[0]]
+	// role definitions name their values _
+	want := len(ast.Tokens)
+	if sec == "g" {
+		want = strings.Count(ast.Value, "_")
+	}
+	if len(rule)-1 != want {
+		return fmt.Errorf("auth: invalid rule %q, %s rules have %d values", rule, rule[0], want)
+	}
+	return nil
+}
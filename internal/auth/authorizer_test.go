@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ledger/config"
+)
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := New(config.ACLModelFile, config.ACLPolicyFile)
+	for _, c := range []struct {
+		subject, object, action string
+		allowed                 bool
+	}{
+		// root is an admin, which is allowed everything
+		{"root", "topic/payments", "produce", true},
+		{"root", "topic/payments", "admin", true},
+		{"root", "cluster", "describe", true},
+		// projection is a reader, so it's read-only
+		{"projection", "topic/payments", "consume", true},
+		{"projection", "cluster", "describe", true},
+		{"projection", "topic/payments", "produce", false},
+		{"projection", "topic/payments", "admin", false},
+		{"nobody", "topic/payments", "consume", false},
+	} {
+		err := authorizer.Authorize(c.subject, c.object, c.action)
+		if c.allowed {
+			require.NoError(t, err, "%s %s %s", c.subject, c.action, c.object)
+		} else {
+			require.Equal(t, codes.PermissionDenied, status.Code(err), "%s %s %s", c.subject, c.action, c.object)
+		}
+	}
+}
+
+func TestAuthorizerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authorizer-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	original, err := ioutil.ReadFile(config.ACLPolicyFile)
+	require.NoError(t, err)
+	policy := filepath.Join(dir, "policy.csv")
+	require.NoError(t, ioutil.WriteFile(policy, original, 0644))
+
+	logged := make(logLines, 10)
+	authorizer := New(config.ACLModelFile, policy)
+	authorizer.Watch(10*time.Millisecond, hclog.New(&hclog.LoggerOptions{Output: logged}))
+	defer authorizer.Close()
+	require.Error(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+
+	// editing the file takes effect without a restart
+	edited := append(append([]byte(nil), original...), "\ng, billing, writer\n"...)
+	require.NoError(t, ioutil.WriteFile(policy, edited, 0644))
+	require.Eventually(t, func() bool {
+		return authorizer.Authorize("billing", "topic/payments", "produce") == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, authorizer.FileRules(), []string{"g", "billing", "writer"})
+
+	// a file that can't be loaded is reported through the logger and leaves the last good policy in place
+	require.NoError(t, ioutil.WriteFile(policy, []byte("g, billing\n"), 0644))
+	select {
+	case line := <-logged:
+		require.Contains(t, line, "[ERROR] reloading ACL policy")
+	case <-time.After(time.Second):
+		t.Fatal("the broken policy file wasn't reported")
+	}
+	require.Error(t, authorizer.Reload())
+	require.NoError(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+}
+
+// Collects what's logged from the goroutine watching the policy file
+type logLines chan string
+
+func (l logLines) Write(p []byte) (int, error) {
+	l <- string(p)
+	return len(p), nil
+}
+
+func TestAuthorizerSetRules(t *testing.T) {
+	authorizer := New(config.ACLModelFile, config.ACLPolicyFile)
+	require.Error(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+
+	require.NoError(t, authorizer.SetRules([][]string{{"g", "billing", "writer"}}))
+	require.NoError(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+	// the rules are enforced on top of the policy file's, which stay in place after a reload
+	require.NoError(t, authorizer.Authorize("root", "cluster", "describe"))
+	require.NoError(t, authorizer.Reload())
+	require.NoError(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+
+	// invalid rules are refused, keeping the rules already set
+	for _, rules := range [][][]string{
+		{{"g", "billing"}},
+		{{"p", "billing", "topic/*"}},
+		{{"x", "billing", "writer"}},
+		{{}},
+	} {
+		err := authorizer.SetRules(rules)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), "%q", rules)
+		require.Equal(t, codes.InvalidArgument, status.Code(authorizer.CheckRule(rules[0])), "%q", rules)
+	}
+	require.NoError(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+	require.NoError(t, authorizer.CheckRule([]string{"p", "billing", "topic/*", "produce"}))
+
+	require.NoError(t, authorizer.SetRules(nil))
+	require.Error(t, authorizer.Authorize("billing", "topic/payments", "produce"))
+}
@@ -1,23 +1,73 @@
 package loadbalancer
 
 import (
+	"context"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+
+	api "ledger/api/v1"
 )
 
 var _ base.V2PickerBuilder = (*Picker)(nil)
 
 type Picker struct {
-	mu        sync.RWMutex
-	leader    balancer.SubConn
+	mu     sync.RWMutex
+	leader balancer.SubConn
+	// leader of each partition
+	leaders   map[uint32]balancer.SubConn
 	followers []balancer.SubConn
 	current   uint64 // index for which follower to pick from
 }
 
+// metadata key the partition a call is for is sent under
+const partitionKey = "ledger-partition"
+
+// Returns a context for calls to the partition, so the picker sends produce calls to the partition's leader
+// Use log.PartitionFor to find a key's partition
+func WithPartition(ctx context.Context, partition uint32) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, partitionKey, strconv.FormatUint(uint64(partition), 10))
+}
+
+// metadata key the consistency level a consume call needs is sent under
+const consistencyKey = "ledger-consistency"
+
+// Returns a context for consume calls that set the consistency level, so the picker sends them to the partition's
+// leader unless they're stale
+func WithConsistency(ctx context.Context, c api.Consistency) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, consistencyKey, c.String())
+}
+
+// Returns the consistency level the call's context asks for, stale if it doesn't say
+func consistency(ctx context.Context) api.Consistency {
+	if ctx == nil {
+		return api.Consistency_STALE
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if v := md.Get(consistencyKey); len(v) > 0 {
+		return api.Consistency(api.Consistency_value[v[len(v)-1]])
+	}
+	return api.Consistency_STALE
+}
+
+// Returns the partition the call's context is for, the first if it doesn't say
+func partition(ctx context.Context) uint32 {
+	if ctx == nil {
+		return 0
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if v := md.Get(partitionKey); len(v) > 0 {
+		p, _ := strconv.ParseUint(v[len(v)-1], 10, 32)
+		return uint32(p)
+	}
+	return 0
+}
+
 func (p *Picker) Build(buildInfo base.PickerBuildInfo) balancer.V2Picker {
 	balancer.Register(
 		base.NewBalancerBuilderV2(Name, p, base.Config{}),
@@ -25,8 +75,13 @@ func (p *Picker) Build(buildInfo base.PickerBuildInfo) balancer.V2Picker {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	var followers []balancer.SubConn
+	leaders := make(map[uint32]balancer.SubConn)
 	for conn, info := range buildInfo.ReadySCs {
 		isLeader := info.Address.Attributes.Value("is_leader").(bool)
+		leaderOf, _ := info.Address.Attributes.Value("leader_of").([]uint32)
+		for _, partition := range leaderOf {
+			leaders[partition] = conn
+		}
 		if isLeader {
 			p.leader = conn
 			continue
@@ -34,6 +89,7 @@ func (p *Picker) Build(buildInfo base.PickerBuildInfo) balancer.V2Picker {
 		followers = append(p.followers, conn)
 	}
 
+	p.leaders = leaders
 	p.followers = followers
 	return p
 }
@@ -45,9 +101,14 @@ func (p *Picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	var result balancer.PickResult
 
 	// inspect the RPC's method name to know whether the call is an produce or consume call
+	// every server has a copy of every partition, so stale consume calls can go to any follower
 	if strings.Contains(info.FullMethodName, "Produce") ||
-		len(p.followers) == 0 {
+		len(p.followers) == 0 ||
+		consistency(info.Ctx) != api.Consistency_STALE {
 		result.SubConn = p.leader
+		if leader, ok := p.leaders[partition(info.Ctx)]; ok {
+			result.SubConn = leader
+		}
 	} else if strings.Contains(info.FullMethodName, "Consume") {
 		result.SubConn = p.nextFollower()
 	}
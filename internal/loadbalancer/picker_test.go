@@ -1,6 +1,7 @@
 package loadbalancer_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -9,6 +10,7 @@ import (
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/resolver"
 
+	api "ledger/api/v1"
 	"ledger/internal/loadbalancer"
 )
 
@@ -42,6 +44,50 @@ func TestPickerConsumesFromFollowers(t *testing.T) {
 	}
 }
 
+func TestPickerConsumesConsistentlyFromLeader(t *testing.T) {
+	picker, subConns := setupTest()
+	info := balancer.PickInfo{
+		FullMethodName: "/log.vX.Log/Consume",
+		Ctx:            loadbalancer.WithConsistency(context.Background(), api.Consistency_LINEARIZABLE),
+	}
+
+	for i := 0; i < 5; i++ {
+		gotPickResult, err := picker.Pick(info)
+		require.NoError(t, err)
+		require.Equal(t, subConns[0], gotPickResult.SubConn)
+	}
+}
+
+func TestPickerProducesToPartitionLeader(t *testing.T) {
+	buildInfo := base.PickerBuildInfo{
+		ReadySCs: make(map[balancer.SubConn]base.SubConnInfo),
+	}
+	// the 0th sub conn leads the first partition and the 2nd leads the second
+	leaderOf := [][]uint32{{0}, nil, {1}}
+	var subConns []*subConn
+	for i := 0; i < 3; i++ {
+		sc := &subConn{}
+		addr := resolver.Address{
+			Attributes: attributes.New("is_leader", i == 0, "leader_of", leaderOf[i]),
+		}
+		sc.UpdateAddresses([]resolver.Address{addr})
+		buildInfo.ReadySCs[sc] = base.SubConnInfo{Address: addr}
+		subConns = append(subConns, sc)
+	}
+	picker := &loadbalancer.Picker{}
+	picker.Build(buildInfo)
+
+	for partition, want := range []int{0, 2} {
+		info := balancer.PickInfo{
+			FullMethodName: "/log.vX.Log/Produce",
+			Ctx:            loadbalancer.WithPartition(context.Background(), uint32(partition)),
+		}
+		gotPickResult, err := picker.Pick(info)
+		require.NoError(t, err)
+		require.Equal(t, subConns[want], gotPickResult.SubConn)
+	}
+}
+
 func setupTest() (*loadbalancer.Picker, []*subConn) {
 	var subConns []*subConn
 	buildInfo := base.PickerBuildInfo{
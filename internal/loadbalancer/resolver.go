@@ -77,10 +77,12 @@ func (r *Resolver) ResolveNow(resolver.ResolveNowOptions) {
 		addrs = append(addrs, resolver.Address{
 			Addr: server.RpcAddr,
 			// attributes are optional but useful
-			// lets us know which server is the leader/follower
+			// lets us know which server is the leader/follower, and of which partitions
 			Attributes: attributes.New(
 				"is_leader",
 				server.IsLeader,
+				"leader_of",
+				server.LeaderOf,
 			),
 		})
 	}
@@ -70,11 +70,11 @@ func TestResolver(t *testing.T) {
 		Addresses: []resolver.Address{
 			{
 				Addr:       "localhost:9001",
-				Attributes: attributes.New("is_leader", true),
+				Attributes: attributes.New("is_leader", true, "leader_of", []uint32{0, 1}),
 			},
 			{
 				Addr:       "localhost:9002",
-				Attributes: attributes.New("is_leader", false),
+				Attributes: attributes.New("is_leader", false, "leader_of", []uint32{2}),
 			},
 		},
 	}
@@ -93,9 +93,11 @@ func (s *getServers) GetServers() ([]*api.Server, error) {
 		Id:       "leader",
 		RpcAddr:  "localhost:9001",
 		IsLeader: true,
+		LeaderOf: []uint32{0, 1},
 	}, {
-		Id:      "follower",
-		RpcAddr: "localhost:9002",
+		Id:       "follower",
+		RpcAddr:  "localhost:9002",
+		LeaderOf: []uint32{2},
 	}}, nil
 }
 
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SegmentArchive stores the files of closed segments away from the log's directory, e.g. in an object store
+//
+// Files are named the same way as in the log's directory, e.g. 16.store, 16.index and 16.timeindex. Each log needs an
+// archive of its own, such as a directory or bucket prefix per node, since segments of different logs share names.
+// Topics sharing an archive store their segments under a "<topic>/" prefix, e.g. payments/16.store
+type SegmentArchive interface {
+	// Put stores the file's contents under the name, replacing any file already stored under it
+	Put(name string, r io.Reader) error
+	// Get opens the file stored under the name
+	Get(name string) (io.ReadCloser, error)
+	// Delete removes the file stored under the name, it isn't an error if there's no such file
+	Delete(name string) error
+}
+
+var _ SegmentArchive = (*DirArchive)(nil)
+
+// Archive backed by a local directory, e.g. on a cheaper or network-mounted disk
+type DirArchive struct {
+	Dir string
+}
+
+func NewDirArchive(dir string) (*DirArchive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DirArchive{Dir: dir}, nil
+}
+
+// Writes the file to a temporary file first and renames it into place, so a file is only ever stored whole
+func (a *DirArchive) Put(name string, r io.Reader) error {
+	dir := filepath.Join(a.Dir, filepath.Dir(name))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(a.Dir, name))
+}
+
+func (a *DirArchive) Get(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(a.Dir, name))
+}
+
+func (a *DirArchive) Delete(name string) error {
+	if err := os.Remove(filepath.Join(a.Dir, name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
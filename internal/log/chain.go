@@ -0,0 +1,312 @@
+package log
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	api "ledger/api/v1"
+)
+
+// Each appended record carries the hash of the record before it, and its own hash covers that, so changing any record
+// changes the hash of every record after it. Checkpoints signed by a node key pin the chain's hash at an offset, so
+// rewriting the whole chain after a change doesn't go unnoticed either.
+
+var errNoSigningKey = errors.New("log: checkpoints need the hash chain enabled and a signing key")
+
+// Returned by Verify at the first offset where the hash chain doesn't hold
+type ErrChainBroken struct {
+	Offset uint64
+	Reason string
+}
+
+func (e ErrChainBroken) Error() string {
+	return fmt.Sprintf("log: hash chain broken at offset %d: %s", e.Offset, e.Reason)
+}
+
+// Returns the record's hash, which covers its offset, timestamp, key, value, checkpoint and the hash of the record
+// before it, but not the Raft term and type, which aren't part of the record's content
+func recordHash(r *api.Record) []byte {
+	h := sha256.New()
+	b := make([]byte, 8)
+	field := func(p []byte) {
+		enc.PutUint64(b, uint64(len(p)))
+		h.Write(b)
+		h.Write(p)
+	}
+	field(r.PrevHash)
+	enc.PutUint64(b, r.Offset)
+	h.Write(b)
+	enc.PutUint64(b, uint64(r.Timestamp))
+	h.Write(b)
+	field(r.Key)
+	field(r.Value)
+	if cp := r.Checkpoint; cp != nil {
+		enc.PutUint64(b, cp.Offset)
+		h.Write(b)
+		field(cp.Hash)
+		field([]byte(cp.KeyId))
+		field(cp.Signature)
+	}
+	return h.Sum(nil)
+}
+
+// What a checkpoint's signature covers
+func checkpointMessage(offset uint64, hash []byte) []byte {
+	b := make([]byte, 0, len("ledger checkpoint")+8+len(hash))
+	b = append(b, "ledger checkpoint"...)
+	b = append(b, make([]byte, 8)...)
+	enc.PutUint64(b[len(b)-8:], offset)
+	return append(b, hash...)
+}
+
+// Reads the last record's hash so the chain carries on from it
+// Must be called with the log's lock held, or before the log's in use
+func (l *Log) loadHead() error {
+	l.head, l.headCheckpoint = nil, false
+	if !l.Config.Chain.Enabled {
+		return nil
+	}
+	next := l.activeSegment.nextOffset
+	lowest := l.segments[0].baseOffset
+	if remote := l.remote(); len(remote) > 0 {
+		lowest = remote[0].BaseOffset
+	}
+	if next == lowest {
+		return nil
+	}
+	var record *api.Record
+	var err error
+	if i := l.segmentFor(next - 1); i != -1 {
+		record, err = l.segments[i].Read(next - 1)
+	} else {
+		record, err = l.readArchivedRecord(next - 1)
+	}
+	if err != nil {
+		return err
+	}
+	l.head, l.headCheckpoint = recordHash(record), record.Checkpoint != nil
+	return nil
+}
+
+// Links the record to the last one appended, before it's written
+// The first record in an empty log keeps the previous hash it came with, so a log restored from a snapshot that
+// starts partway through the chain carries on the same chain
+// Must be called with the log's lock held
+func (l *Log) link(record *api.Record) {
+	if l.Config.Chain.Enabled && l.head != nil {
+		record.PrevHash = l.head
+	}
+}
+
+// Makes the record, now that it's written, the one the next record links to
+// Must be called with the log's lock held
+func (l *Log) linked(record *api.Record) {
+	if l.Config.Chain.Enabled {
+		l.head, l.headCheckpoint = recordHash(record), record.Checkpoint != nil
+	}
+}
+
+// Builds a checkpoint vouching for the last record appended, or returns nil if there's nothing to vouch for because the
+// log is empty or its last record is already a checkpoint
+// Must be called with the log's lock held
+func (l *Log) checkpointRecord() (*api.Record, error) {
+	c := l.Config.Chain
+	if !c.Enabled || c.SigningKey == nil {
+		return nil, errNoSigningKey
+	}
+	if l.head == nil || l.headCheckpoint {
+		return nil, nil
+	}
+	off := l.activeSegment.nextOffset - 1
+	return &api.Record{
+		Checkpoint: &api.Checkpoint{
+			Offset:    off,
+			Hash:      l.head,
+			KeyId:     c.SigningKeyID,
+			Signature: ed25519.Sign(c.SigningKey, checkpointMessage(off, l.head)),
+		},
+	}, nil
+}
+
+// Checkpoint appends a checkpoint signed with the log's signing key, vouching for every record appended so far, and
+// returns its offset
+// If the last record is already a checkpoint, nothing is appended and its offset is returned
+func (l *Log) Checkpoint() (uint64, error) {
+	off, wait, err := l.checkpoint()
+	if err != nil {
+		return 0, err
+	}
+	return off, wait()
+}
+
+// Appends a checkpoint and returns its offset along with a function that blocks until it's durable
+func (l *Log) checkpoint() (uint64, func() error, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record, err := l.checkpointRecord()
+	if err != nil {
+		return 0, nil, err
+	}
+	if record == nil {
+		if l.head == nil {
+			return 0, nil, errors.New("log: there are no records to checkpoint")
+		}
+		return l.activeSegment.nextOffset - 1, func() error { return nil }, nil
+	}
+	off, err := l.write(record)
+	if err != nil {
+		return 0, nil, err
+	}
+	return off, l.commit(1), nil
+}
+
+// What Verify checked
+type Verification struct {
+	// records whose hashes were recomputed
+	Records uint64
+	// checkpoints whose signatures, and hashes where the record they vouch for is still around, checked out
+	Checkpoints uint64
+	// places where compaction removed the record before another, so the link between them couldn't be checked
+	// only a log with Compaction.Enabled can have them, in any other log a missing record breaks the chain
+	Gaps uint64
+}
+
+// Verify recomputes the hash chain over the records from offset from up to, but not including, offset to, and checks
+// each checkpoint's signature and hash along the way
+//
+// Returns ErrChainBroken with the first offset where the chain doesn't hold. The first record's link to the records
+// before the range isn't checked, so verify from the lowest offset to check the whole log.
+func (l *Log) Verify(from, to uint64) (Verification, error) {
+	var v Verification
+	keys := make(map[string]ed25519.PublicKey, len(l.Config.Chain.VerifyKeys)+1)
+	for id, key := range l.Config.Chain.VerifyKeys {
+		keys[id] = key
+	}
+	if key := l.Config.Chain.SigningKey; key != nil {
+		keys[l.Config.Chain.SigningKeyID] = key.Public().(ed25519.PublicKey)
+	}
+
+	var prevHash []byte
+	var prevOffset uint64
+	it := l.Iterator(from, to, 0)
+	for it.Next() {
+		r := it.Record()
+		switch {
+		case prevHash == nil:
+		case r.Offset != prevOffset+1 && l.Config.Compaction.Enabled:
+			v.Gaps++
+		case r.Offset != prevOffset+1:
+			return v, ErrChainBroken{
+				Offset: r.Offset,
+				Reason: fmt.Sprintf("the records after offset %d are missing and the log isn't compacted", prevOffset),
+			}
+		case !bytes.Equal(r.PrevHash, prevHash):
+			return v, ErrChainBroken{
+				Offset: r.Offset,
+				Reason: "the record doesn't link to the one before it, one of them was changed",
+			}
+		}
+		hash := recordHash(r)
+		if r.Checkpoint != nil {
+			if err := l.verifyCheckpoint(r, keys, prevOffset, prevHash); err != nil {
+				return v, err
+			}
+			v.Checkpoints++
+		}
+		v.Records++
+		prevHash, prevOffset = hash, r.Offset
+	}
+	return v, it.Err()
+}
+
+// Checks the checkpoint's signature, and that the record it vouches for still has the hash it was signed with
+// prevOffset and prevHash are the record just before the checkpoint, if it's been verified
+func (l *Log) verifyCheckpoint(
+	r *api.Record,
+	keys map[string]ed25519.PublicKey,
+	prevOffset uint64,
+	prevHash []byte,
+) error {
+	cp := r.Checkpoint
+	key, ok := keys[cp.KeyId]
+	if !ok {
+		return ErrChainBroken{Offset: r.Offset, Reason: fmt.Sprintf("checkpoint signed with unknown key %q", cp.KeyId)}
+	}
+	if !ed25519.Verify(key, checkpointMessage(cp.Offset, cp.Hash), cp.Signature) {
+		return ErrChainBroken{Offset: r.Offset, Reason: "checkpoint signature doesn't match"}
+	}
+	if cp.Offset >= r.Offset {
+		return ErrChainBroken{Offset: r.Offset, Reason: "checkpoint vouches for a later record"}
+	}
+	hash := prevHash
+	if prevHash == nil || cp.Offset != prevOffset {
+		record, err := l.Read(cp.Offset)
+		if _, ok := err.(api.ErrOffsetOutOfRange); ok {
+			// deleted by retention, so only the signature can be checked
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if record.Offset != cp.Offset {
+			// removed by compaction
+			return nil
+		}
+		hash = recordHash(record)
+	}
+	if !bytes.Equal(hash, cp.Hash) {
+		return ErrChainBroken{
+			Offset: cp.Offset,
+			Reason: fmt.Sprintf("the record doesn't match the checkpoint at offset %d", r.Offset),
+		}
+	}
+	return nil
+}
+
+// Reads the key checkpoints are signed with from a file holding a single "<id>:<base64 key>" entry, where the key is
+// an ed25519 seed or private key
+func LoadSigningKey(file string) (string, ed25519.PrivateKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	keys, id, err := parseKeys(string(b))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) != 1 {
+		return "", nil, fmt.Errorf("%s: expected a single signing key, found %d", file, len(keys))
+	}
+	switch key := keys[id]; len(key) {
+	case ed25519.SeedSize:
+		return id, ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return id, ed25519.PrivateKey(key), nil
+	default:
+		return "", nil, fmt.Errorf("signing key %q: expected an ed25519 seed or private key", id)
+	}
+}
+
+// Reads the public keys checkpoints are verified with from a file of "<id>:<base64 key>" entries
+func LoadVerifyKeys(file string) (map[string]ed25519.PublicKey, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	entries, _, err := parseKeys(string(b))
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]ed25519.PublicKey, len(entries))
+	for id, key := range entries {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("verify key %q: expected an ed25519 public key", id)
+		}
+		keys[id] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,153 @@
+package log
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "ledger/api/v1"
+)
+
+func TestHashChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	c := Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Chain.Enabled = true
+	c.Chain.SigningKey = key
+	c.Chain.SigningKeyID = "node-1"
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendN := func(n int) {
+		for i := 0; i < n; i++ {
+			_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+			require.NoError(t, err)
+		}
+	}
+	appendN(10)
+	off, err := l.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), off)
+	// nothing new to vouch for
+	off, err = l.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), off)
+
+	// the chain carries on from the last record after a restart
+	require.NoError(t, l.Close())
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	appendN(5)
+	_, err = l.Checkpoint()
+	require.NoError(t, err)
+
+	v, err := l.Verify(0, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, Verification{Records: 17, Checkpoints: 2}, v)
+
+	// checkpoints signed with a key we don't know aren't trusted
+	l.Config.Chain.SigningKey = nil
+	_, err = l.Verify(0, math.MaxUint64)
+	require.Equal(t, ErrChainBroken{Offset: 10, Reason: `checkpoint signed with unknown key "node-1"`}, err)
+	l.Config.Chain.VerifyKeys = map[string]ed25519.PublicKey{"node-1": key.Public().(ed25519.PublicKey)}
+	_, err = l.Verify(0, math.MaxUint64)
+	require.NoError(t, err)
+
+	// changing a record breaks its link to the next one
+	rewrite(t, l, 3, func(r *api.Record) { r.Value = []byte("record-X") })
+	_, err = l.Verify(0, math.MaxUint64)
+	require.Equal(t, ErrChainBroken{
+		Offset: 4,
+		Reason: "the record doesn't link to the one before it, one of them was changed",
+	}, err)
+
+	// relinking every record after it still doesn't match the signed checkpoint
+	for off := uint64(4); off < 17; off++ {
+		prev, err := l.Read(off - 1)
+		require.NoError(t, err)
+		rewrite(t, l, off, func(r *api.Record) { r.PrevHash = recordHash(prev) })
+	}
+	_, err = l.Verify(0, math.MaxUint64)
+	require.Equal(t, ErrChainBroken{
+		Offset: 9,
+		Reason: "the record doesn't match the checkpoint at offset 10",
+	}, err)
+}
+
+// Overwrites the record at the offset in place, which only works if it keeps the same size
+func rewrite(t *testing.T, l *Log, off uint64, fn func(*api.Record)) {
+	record, err := l.Read(off)
+	require.NoError(t, err)
+	fn(record)
+	p, err := encodeRecord(record, l.Config.Segment.Codec, l.Config.Encryption.Keyring)
+	require.NoError(t, err)
+
+	s := l.segments[l.segmentFor(off)]
+	c, err := s.seek(off - s.baseOffset)
+	require.NoError(t, err)
+	width, err := s.store.Width(c.pos)
+	require.NoError(t, err)
+	require.Equal(t, width, headerWidth+uint64(len(p)))
+	f, err := os.OpenFile(s.store.Name(), os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteAt(append(frameHeader(p), p...), int64(c.pos))
+	require.NoError(t, err)
+}
+
+func TestHashChainGaps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain-gaps-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := Config{}
+	c.Chain.Enabled = true
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	defer l.Close()
+	for i := 0; i < 10; i++ {
+		_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+
+	// the same records with one taken out of the middle of the segment
+	cutDir, err := ioutil.TempDir("", "chain-gaps-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cutDir)
+	cut, err := NewLog(cutDir, c)
+	require.NoError(t, err)
+	defer cut.Close()
+	for off := uint64(0); off < 10; off++ {
+		if off == 4 {
+			continue
+		}
+		record, err := l.Read(off)
+		require.NoError(t, err)
+		require.NoError(t, cut.appendAt(record))
+	}
+
+	// a log that isn't compacted can't lose records, so the gap breaks the chain
+	_, err = cut.Verify(0, math.MaxUint64)
+	require.Equal(t, ErrChainBroken{
+		Offset: 5,
+		Reason: "the records after offset 3 are missing and the log isn't compacted",
+	}, err)
+
+	// compaction leaves gaps like it, which are counted instead
+	cut.Config.Compaction.Enabled = true
+	v, err := cut.Verify(0, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, Verification{Records: 9, Gaps: 1}, v)
+}
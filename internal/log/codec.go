@@ -0,0 +1,269 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+
+	api "ledger/api/v1"
+)
+
+// Codec compresses records before they're written to a segment's store
+//
+// Each record in the store starts with attributes holding the codec it was written with, so a log keeps reading
+// correctly after its codec is changed
+//
+// A batch appended with AppendBatch is compressed as a whole, in a single frame of the store, so the codec can make
+// use of what the batch's records have in common. Reading any record in the batch decompresses the whole batch, and
+// compaction rewrites a batch without the records it drops. A record appended on its own is compressed by itself, and
+// anything that doesn't get smaller is stored uncompressed.
+type Codec uint8
+
+const (
+	CodecNone Codec = iota
+	CodecGzip
+	CodecSnappy
+)
+
+const (
+	// number of bytes used to store a record's attributes in front of it
+	attrWidth = 1
+	// the low bits of a record's attributes hold its codec
+	attrCodecMask = 0x0f
+	// set when the record is encrypted
+	attrEncrypted = 0x10
+	// set when the record's timestamp follows its attributes, so it can be read without decoding the record
+	attrTimestamp = 0x20
+	// set when the frame holds a batch rather than a single record: the number of records follows the attributes,
+	// then the records, each one encoded on its own and prefixed by its length, compressed and encrypted together
+	attrBatch = 0x40
+	// number of bytes used to store the number of records in a batch, and the length of each record
+	batchLenWidth = 4
+)
+
+// Marshals the record, compresses it with the codec and encrypts it with the keyring's active key, if there's a
+// keyring
+// Records that don't get any smaller are stored uncompressed
+func encodeRecord(record *api.Record, codec Codec, keyring *Keyring) ([]byte, error) {
+	// the timestamp lives in the header rather than the body
+	timestamp := record.Timestamp
+	record.Timestamp = 0
+	b, err := proto.Marshal(record)
+	record.Timestamp = timestamp
+	if err != nil {
+		return nil, err
+	}
+	attrs := byte(CodecNone) | attrTimestamp
+	if codec != CodecNone {
+		compressed, err := compress(b, codec)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(b) {
+			attrs, b = attrs|byte(codec), compressed
+		}
+	}
+	// compress before encrypting since ciphertext doesn't compress
+	if keyring != nil {
+		if b, err = keyring.seal(b, nil); err != nil {
+			return nil, err
+		}
+		attrs |= attrEncrypted
+	}
+	header := make([]byte, attrWidth+tsWidth)
+	header[0] = attrs
+	enc.PutUint64(header[attrWidth:], uint64(timestamp))
+	return append(header, b...), nil
+}
+
+// Encodes the records, which must have consecutive offsets, as one frame, compressing them together with the codec
+// and encrypting them with the keyring's active key, if there's a keyring
+func encodeBatch(records []*api.Record, codec Codec, keyring *Keyring) ([]byte, error) {
+	var b []byte
+	size := make([]byte, batchLenWidth)
+	for _, record := range records {
+		p, err := encodeRecord(record, CodecNone, nil)
+		if err != nil {
+			return nil, err
+		}
+		enc.PutUint32(size, uint32(len(p)))
+		b = append(append(b, size...), p...)
+	}
+	attrs := byte(CodecNone) | attrBatch
+	if codec != CodecNone {
+		compressed, err := compress(b, codec)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(b) {
+			attrs, b = attrs|byte(codec), compressed
+		}
+	}
+	if keyring != nil {
+		var err error
+		if b, err = keyring.seal(b, nil); err != nil {
+			return nil, err
+		}
+		attrs |= attrEncrypted
+	}
+	header := make([]byte, attrWidth+batchLenWidth)
+	header[0] = attrs
+	enc.PutUint32(header[attrWidth:], uint32(len(records)))
+	return append(header, b...), nil
+}
+
+// Returns the content of each record in the frame, decrypting and decompressing a batch, so each can be decoded with
+// decodeRecord
+func decodeFrame(p []byte, keyring *Keyring) ([][]byte, error) {
+	if len(p) < attrWidth || p[0]&attrBatch == 0 {
+		return [][]byte{p}, nil
+	}
+	if len(p) < attrWidth+batchLenWidth {
+		return nil, errCorrupt
+	}
+	attrs, n, b := p[0], enc.Uint32(p[attrWidth:]), p[attrWidth+batchLenWidth:]
+	var err error
+	if attrs&attrEncrypted != 0 {
+		if b, err = keyring.open(b, nil); err != nil {
+			return nil, err
+		}
+	}
+	if b, err = decompress(b, Codec(attrs&attrCodecMask)); err != nil {
+		return nil, err
+	}
+	records := make([][]byte, 0, n)
+	for len(b) > 0 {
+		if len(b) < batchLenWidth || uint64(len(b)-batchLenWidth) < uint64(enc.Uint32(b)) {
+			return nil, errCorrupt
+		}
+		size := batchLenWidth + int(enc.Uint32(b))
+		records, b = append(records, b[batchLenWidth:size]), b[size:]
+	}
+	if uint32(len(records)) != n {
+		return nil, errCorrupt
+	}
+	return records, nil
+}
+
+// Returns the number of records in the frame whose content starts with head, which needs to hold at least
+// attrWidth+batchLenWidth bytes for a batch
+func frameCount(head []byte) uint64 {
+	if len(head) < attrWidth+batchLenWidth || head[0]&attrBatch == 0 {
+		return 1
+	}
+	if n := enc.Uint32(head[attrWidth:]); n > 0 {
+		return uint64(n)
+	}
+	return 1
+}
+
+// Decrypts and decompresses the record according to its attributes and unmarshals it
+func decodeRecord(p []byte, keyring *Keyring) (*api.Record, error) {
+	if len(p) < attrWidth {
+		return nil, errCorrupt
+	}
+	attrs, b := p[0], p[attrWidth:]
+	var timestamp int64
+	if attrs&attrTimestamp != 0 {
+		if uint64(len(b)) < tsWidth {
+			return nil, errCorrupt
+		}
+		timestamp, b = int64(enc.Uint64(b[:tsWidth])), b[tsWidth:]
+	}
+	var err error
+	if attrs&attrEncrypted != 0 {
+		if b, err = keyring.open(b, nil); err != nil {
+			return nil, err
+		}
+	}
+	if b, err = decompress(b, Codec(attrs&attrCodecMask)); err != nil {
+		return nil, err
+	}
+	record := &api.Record{}
+	if err = proto.Unmarshal(b, record); err != nil {
+		return nil, err
+	}
+	record.Timestamp = timestamp
+	return record, nil
+}
+
+// Decodes the record at the offset like decodeRecord, reporting a record that's malformed or fails to decrypt as
+// api.ErrCorruptRecord
+func decodeRecordAt(p []byte, offset uint64, keyring *Keyring) (*api.Record, error) {
+	record, err := decodeRecord(p, keyring)
+	if err == errCorrupt {
+		return nil, api.ErrCorruptRecord{Offset: offset}
+	}
+	return record, err
+}
+
+// Returns the timestamp in the record's header without decoding the record, or 0 for records written before
+// records had timestamps
+func recordTimestamp(p []byte) int64 {
+	if len(p) < attrWidth+int(tsWidth) || p[0]&attrTimestamp == 0 {
+		return 0
+	}
+	return int64(enc.Uint64(p[attrWidth : attrWidth+tsWidth]))
+}
+
+func compress(b []byte, codec Codec) ([]byte, error) {
+	switch codec {
+	case CodecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CodecSnappy:
+		return snappy.Encode(nil, b), nil
+	}
+	return nil, fmt.Errorf("unknown codec: %d", codec)
+}
+
+func decompress(b []byte, codec Codec) ([]byte, error) {
+	switch codec {
+	case CodecNone:
+		return b, nil
+	case CodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case CodecSnappy:
+		return snappy.Decode(nil, b)
+	}
+	return nil, fmt.Errorf("unknown codec: %d", codec)
+}
+
+// Parses a codec from its name: "none", "gzip" or "snappy"
+func ParseCodec(name string) (Codec, error) {
+	for codec, n := range codecNames {
+		if n == name {
+			return Codec(codec), nil
+		}
+	}
+	return CodecNone, fmt.Errorf("unknown codec: %q", name)
+}
+
+func (c Codec) String() string {
+	if int(c) < len(codecNames) {
+		return codecNames[c]
+	}
+	return fmt.Sprintf("Codec(%d)", c)
+}
+
+var codecNames = []string{
+	CodecNone:   "none",
+	CodecGzip:   "gzip",
+	CodecSnappy: "snappy",
+}
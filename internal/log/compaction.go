@@ -0,0 +1,221 @@
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	api "ledger/api/v1"
+)
+
+const (
+	// where a compacted segment is written before it replaces the original
+	compactingDir = "compacting"
+	// once a compacted segment is complete, compactingDir is renamed to this
+	// a crash after that point is finished on startup by moving its files into place
+	swappingDir = "swapping"
+)
+
+// Compacts closed segments every interval until the log is closed
+func (l *Log) compactionLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Compact(); err != nil {
+				l.Config.Logger.Error("compacting", "dir", l.Dir, "error", err)
+			}
+		}
+	}
+}
+
+// Compact rewrites the closed segments so that only the latest record for each key remains
+//
+// Records without a key are always kept, and records keep their offsets, so consumers reading a compacted offset get
+// the next record after it. The last record of each segment is always kept too, so the segment still knows where it
+// ends and a read never has to look past it. Records appended as a batch are written back as a batch, without the
+// records dropped from it. Each segment is swapped in atomically, so readers never see a partly compacted segment.
+func (l *Log) Compact() error {
+	if l.readOnly {
+		return errReadOnly
+	}
+	l.compactMu.Lock()
+	defer l.compactMu.Unlock()
+
+	l.mu.RLock()
+	segments := make([]*segment, len(l.segments))
+	copy(segments, l.segments)
+	end := l.activeSegment.nextOffset
+	l.mu.RUnlock()
+
+	// find the latest offset of each key across the whole log, including the active segment
+	latest := make(map[string]uint64)
+	for _, s := range segments {
+		err := l.scan(s, end, func(record *api.Record, _ cursor) error {
+			if len(record.Key) > 0 {
+				latest[string(record.Key)] = record.Offset
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range segments[:len(segments)-1] {
+		if err := l.compact(s, latest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewrites the segment without the records superseded by a later record with the same key
+func (l *Log) compact(s *segment, latest map[string]uint64) error {
+	tmpDir := filepath.Join(l.Dir, compactingDir)
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	c, err := newSegment(tmpDir, s.baseOffset, l.Config)
+	if err != nil {
+		return err
+	}
+	var dropped bool
+	// the records kept from the batch being scanned, which are written back as a batch while they're consecutive
+	var batch []*api.Record
+	var batchPos uint64
+	err = l.scan(s, s.nextOffset, func(record *api.Record, at cursor) error {
+		last := record.Offset == s.nextOffset-1
+		if len(record.Key) > 0 && latest[string(record.Key)] != record.Offset && !last {
+			dropped = true
+			return nil
+		}
+		if n := len(batch); n > 0 && (at.pos != batchPos || batch[n-1].Offset+1 != record.Offset) {
+			// records keep their offsets rather than taking the next ones in the compacted segment
+			if err := c.appendBatch(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+		batch, batchPos = append(batch, record), at.pos
+		return nil
+	})
+	if err == nil {
+		err = c.appendBatch(batch)
+	}
+	if err == nil {
+		err = c.Sync()
+	}
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || !dropped {
+		return err
+	}
+
+	// keep the original modification time so retention still ages the segment from its last append
+	fi, err := os.Stat(s.store.Name())
+	if err != nil {
+		return err
+	}
+	if err = os.Chtimes(c.store.Name(), fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := l.segmentIndex(s)
+	if i == -1 {
+		// retention deleted the segment while we were compacting it
+		return nil
+	}
+	if err = s.Close(); err != nil {
+		return err
+	}
+	// renaming the directory is the point where the compacted segment is committed
+	if err = os.Rename(tmpDir, filepath.Join(l.Dir, swappingDir)); err != nil {
+		return err
+	}
+	if err = l.finishSwap(); err != nil {
+		return err
+	}
+	n, err := newSegment(l.Dir, s.baseOffset, l.Config)
+	if err != nil {
+		return err
+	}
+	l.segments[i] = n
+	// the archived copy still has the records we dropped, so the segment's uploaded again
+	return l.forgetArchived(s.baseOffset)
+}
+
+// Moves a committed compacted segment into place, replacing the original
+func (l *Log) finishSwap() error {
+	dir := filepath.Join(l.Dir, swappingDir)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err = os.Rename(
+			filepath.Join(dir, file.Name()),
+			filepath.Join(l.Dir, file.Name()),
+		); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// Calls fn with every record in the segment before the given offset, in order, along with where the record is
+func (l *Log) scan(s *segment, end uint64, fn func(*api.Record, cursor) error) error {
+	// the active segment is still being appended to, so we can't read its index without the lock
+	l.mu.RLock()
+	c, err := s.seek(0)
+	l.mu.RUnlock()
+	for err == nil && s.baseOffset+c.off < end {
+		var p []byte
+		var f frameInfo
+		var record *api.Record
+		if p, f, err = s.readAt(c); err != nil {
+			return err
+		}
+		if record, err = decodeRecord(p, l.Config.Encryption.Keyring); err != nil {
+			return err
+		}
+		if err = fn(record, c); err != nil {
+			return err
+		}
+		l.mu.RLock()
+		c, err = s.next(c, f)
+		l.mu.RUnlock()
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Returns the position of the segment in the log, or -1 if it's no longer part of the log
+// Must be called with the log's lock held
+func (l *Log) segmentIndex(s *segment) int {
+	for i, segment := range l.segments {
+		if segment == s {
+			return i
+		}
+	}
+	return -1
+}
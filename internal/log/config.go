@@ -1,24 +1,155 @@
 package log
 
 import (
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/raft"
 )
 
 // Config to build the log or distributed log
 type Config struct {
+	// where errors from background work and records discarded on recovery are reported, the Raft layer logs through
+	// it too, defaults to a logger named ledger writing to stderr
+	Logger hclog.Logger
 	// Raft configuration
 	Raft struct {
 		raft.Config
 		StreamLayer *StreamLayer
 		Bootstrap   bool
+		// Raft groups every topic is split across, each with a leader of its own so writes are spread over the
+		// cluster, defaults to 1
+		Partitions uint32
+		// how often each partition's leader checks whether to hand leadership over so leaders stay spread across
+		// the servers, defaults to 10 seconds
+		BalanceInterval time.Duration
+		// ACL rules replicated through the first partition's Raft group, nil if there aren't any
+		Policy *Policy
 	}
 	//
 	Segment struct {
 		// specify the initial offset of the log
 		InitialOffset uint64
-		// max size of a segment's store
+		// max size of a segment's store, defaults to 1GiB, which the last batch appended to the segment may run past
 		MaxStoreBytes uint64
-		// max size of a store's index
+		// max size of a store's index, defaults to 10MiB, enough for 655,360 records
 		MaxIndexBytes uint64
+		// makes the index sparse, with an entry only every this many bytes of records, so it takes up far less space
+		// for small records at the cost of scanning up to this many bytes of the store on each lookup
+		// 0 indexes every record
+		IndexIntervalBytes uint64
+		// bytes appended between entries in a segment's time index, defaults to 4KiB
+		TimeIndexIntervalBytes uint64
+		// compression for newly appended records, records already written keep the codec they were written with
+		// a batch appended with AppendBatch is compressed as a whole, see Codec
+		Codec Codec
+		// when appended records are forced to disk, and so when an append returns
+		Sync SyncPolicy
+		// with SyncBatch, fsync once this many records are waiting
+		SyncEveryRecords uint64
+		// with SyncBatch, fsync waiting records at least this often
+		SyncInterval time.Duration
+	}
+	// encryption at rest for records appended to the log
+	Encryption struct {
+		// keys records are encrypted with, nil leaves new records unencrypted
+		Keyring *Keyring
+	}
+	// tamper-evident hash chain over the log's records, see Log.Verify
+	Chain struct {
+		// each appended record carries the hash of the record before it
+		Enabled bool
+		// key the checkpoints this node writes are signed with, nil means it doesn't write any
+		SigningKey ed25519.PrivateKey
+		// ID of SigningKey, recorded in each checkpoint so verifiers know which key to check it with
+		SigningKeyID string
+		// public keys checkpoints are verified with, by ID, SigningKey's public key is always included
+		VerifyKeys map[string]ed25519.PublicKey
+		// how often the distributed log's leader writes a checkpoint if records were appended since the last one,
+		// defaults to a minute
+		CheckpointInterval time.Duration
+	}
+	// rules for deleting old segments in the background
+	// only whole segments the log has rolled past are deleted, and always oldest first
+	Retention struct {
+		// delete the oldest segments while the log holds more than this many bytes, 0 means no limit
+		MaxBytes uint64
+		// delete segments that haven't been appended to for this long, 0 means no limit
+		MaxAge time.Duration
+		// never delete a segment holding this offset or any after it, 0 leaves it to MaxBytes and MaxAge
+		MinOffset uint64
+		// how often to check the log against the rules, defaults to a minute
+		CheckInterval time.Duration
+	}
+	// tiered storage: closed segments are uploaded to an archive, after which the oldest can be deleted from local
+	// disk and are fetched back from the archive when they're read, see Log.Offload
+	Tiering struct {
+		// where closed segments are uploaded, nil keeps every segment on local disk only
+		Archive SegmentArchive
+		// delete the local copies of the oldest uploaded segments while the local segments hold more than this many
+		// bytes, 0 keeps uploaded segments on local disk too
+		LocalBytes uint64
+		// bytes of segments fetched back from the archive to keep on local disk for later reads, defaults to 1GiB
+		// the segment read last is always kept, however big it is
+		CacheBytes uint64
+		// how often to upload closed segments, defaults to a minute
+		Interval time.Duration
 	}
+	// key-based compaction, see Log.Compact
+	Compaction struct {
+		// compact closed segments in the background so only the latest record for each key remains
+		Enabled bool
+		// how often to compact, defaults to ten minutes
+		Interval time.Duration
+	}
+	// settings for single topics by name, in place of the log-wide ones, every server must use the same ones
+	Topics map[string]TopicConfig
+}
+
+// TopicConfig replaces the log-wide retention, compaction and tiering settings for one topic, so a compacted topic
+// of account balances can sit beside a journal that keeps every record
+// zero values mean no limit, no compaction and keeping uploaded segments on local disk
+type TopicConfig struct {
+	RetentionMaxBytes uint64
+	RetentionMaxAge   time.Duration
+	Compaction        bool
+	ArchiveLocalBytes uint64
+}
+
+// SyncPolicy decides when appended records are fsynced
+type SyncPolicy uint8
+
+const (
+	// records are handed to the OS on every append, and the OS decides when they reach the disk
+	SyncNone SyncPolicy = iota
+	// every append is fsynced before it returns
+	SyncAlways
+	// group commit: appends wait until SyncEveryRecords records are waiting or SyncInterval passes,
+	// then a single fsync covers all of them
+	SyncBatch
+)
+
+// Parses a sync policy from its name: "none", "always" or "batch"
+func ParseSyncPolicy(name string) (SyncPolicy, error) {
+	for policy, n := range syncPolicyNames {
+		if n == name {
+			return SyncPolicy(policy), nil
+		}
+	}
+	return SyncNone, fmt.Errorf("unknown sync policy: %q", name)
+}
+
+func (p SyncPolicy) String() string {
+	if int(p) < len(syncPolicyNames) {
+		return syncPolicyNames[p]
+	}
+	return fmt.Sprintf("SyncPolicy(%d)", p)
+}
+
+var syncPolicyNames = []string{
+	SyncNone:   "none",
+	SyncAlways: "always",
+	SyncBatch:  "batch",
 }
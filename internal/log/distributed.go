@@ -1,18 +1,22 @@
 package log
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/raft"
 
 	api "ledger/api/v1"
@@ -23,7 +27,7 @@ func NewDistributedLog(dataDir string, config Config) (
 	error,
 ) {
 	l := &DistributedLog{
-		config: config,
+		config: withDefaults(config),
 	}
 	if err := l.setupLog(dataDir); err != nil {
 		return nil, err
@@ -31,14 +35,26 @@ func NewDistributedLog(dataDir string, config Config) (
 	if err := l.setupRaft(dataDir); err != nil {
 		return nil, err
 	}
+	l.stop = make(chan struct{})
+	if config.Chain.Enabled && config.Chain.SigningKey != nil {
+		interval := config.Chain.CheckpointInterval
+		if interval == 0 {
+			interval = time.Minute
+		}
+		l.background.Add(1)
+		go l.checkpointLoop(interval)
+	}
 	return l, nil
 }
 
 // Fault-tolerant and scalable distributed log
 type DistributedLog struct {
 	config Config
-	log    *Log
+	topics *Topics
 	raft   *raft.Raft
+	// closed to stop writing checkpoints
+	stop       chan struct{}
+	background sync.WaitGroup
 }
 
 func (l *DistributedLog) setupLog(dataDir string) error {
@@ -47,12 +63,28 @@ func (l *DistributedLog) setupLog(dataDir string) error {
 		return err
 	}
 	var err error
-	l.log, err = NewLog(logDir, l.config)
+	l.topics, err = NewTopics(logDir, l.config)
 	return err
 }
 
+// Lets operators know when a restart had to throw away a partially written record
+func (l *Log) reportRecovery() {
+	r := l.Recovery()
+	if r.DiscardedBytes == 0 && r.DiscardedEntries == 0 {
+		return
+	}
+	l.Config.Logger.Warn(
+		"discarded a partially written record on recovery",
+		"dir", l.Dir,
+		"bytes", r.DiscardedBytes,
+		"index_entries", r.DiscardedEntries,
+		"segment", r.BaseOffset,
+		"next_offset", r.NextOffset,
+	)
+}
+
 func (l *DistributedLog) setupRaft(dataDir string) error {
-	fsm := &fsm{log: l.log}
+	fsm := &fsm{topics: l.topics, policy: l.config.Raft.Policy}
 
 	logDir := filepath.Join(dataDir, "raft", "log")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
@@ -61,6 +93,13 @@ func (l *DistributedLog) setupRaft(dataDir string) error {
 	logConfig := l.config
 	// Set the intial offset to 1, required by Raft
 	logConfig.Segment.InitialOffset = 1
+	// Raft drops entries from its own log through DeleteRange once they're in a snapshot
+	logConfig.Retention.MaxBytes = 0
+	logConfig.Retention.MaxAge = 0
+	logConfig.Compaction.Enabled = false
+	logConfig.Chain.Enabled = false
+	// only the records are tiered, Raft's entries are deleted once they're in a snapshot
+	logConfig.Tiering.Archive = nil
 	logStore, err := newLogStore(logDir, logConfig)
 	if err != nil {
 		return err
@@ -77,10 +116,12 @@ func (l *DistributedLog) setupRaft(dataDir string) error {
 
 	// `retain` specifies the number of snapshots we'll keep
 	retain := 1
-	snapshotStore, err := raft.NewFileSnapshotStore(
+	raftLogger := l.config.Logger.Named("raft")
+	stdLogger := raftLogger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true})
+	snapshotStore, err := raft.NewFileSnapshotStoreWithLogger(
 		filepath.Join(dataDir, "raft"),
 		retain,
-		os.Stderr,
+		stdLogger,
 	)
 	if err != nil {
 		return err
@@ -88,14 +129,15 @@ func (l *DistributedLog) setupRaft(dataDir string) error {
 
 	maxPool := 5
 	timeout := 10 * time.Second
-	transport := raft.NewNetworkTransport(
+	transport := raft.NewNetworkTransportWithLogger(
 		l.config.Raft.StreamLayer,
 		maxPool,
 		timeout,
-		os.Stderr,
+		stdLogger,
 	)
 
 	config := raft.DefaultConfig()
+	config.Logger = raftLogger
 	config.LocalID = l.config.Raft.LocalID
 	if l.config.Raft.HeartbeatTimeout != 0 {
 		config.HeartbeatTimeout = l.config.Raft.HeartbeatTimeout
@@ -133,15 +175,81 @@ func (l *DistributedLog) setupRaft(dataDir string) error {
 	return err
 }
 
-func (l *DistributedLog) Append(record *api.Record) (uint64, error) {
+func (l *DistributedLog) Append(topic string, record *api.Record) (uint64, error) {
+	// stamp the record here rather than in the FSM so every server stores the same timestamp
+	if record.Timestamp == 0 {
+		record.Timestamp = time.Now().UnixNano()
+	}
+	// only the leader writes checkpoints
+	record.Checkpoint = nil
 	res, err := l.apply(
 		AppendRequestType,
-		&api.ProduceRequest{Record: record},
+		&api.ProduceRequest{Record: record, Topic: topic},
 	)
 	if err != nil {
 		return 0, err
 	}
-	return res.(*api.ProduceResponse).Offset, nil
+	// only acknowledge the record once it's as durable as the sync policy asks for
+	append := res.(*appendResponse)
+	if err = append.wait(); err != nil {
+		return 0, err
+	}
+	return append.offset, nil
+}
+
+// Appends the records in a single Raft log entry, so the batch costs one round trip to the followers
+// Either every record is appended, at consecutive offsets, or none of them are
+func (l *DistributedLog) AppendBatch(topic string, records []*api.Record) ([]uint64, error) {
+	now := time.Now().UnixNano()
+	for _, record := range records {
+		if record.Timestamp == 0 {
+			record.Timestamp = now
+		}
+		record.Checkpoint = nil
+	}
+	res, err := l.apply(
+		AppendBatchRequestType,
+		&api.ProduceBatchRequest{Records: records, Topic: topic},
+	)
+	if err != nil {
+		return nil, err
+	}
+	batch := res.(*appendBatchResponse)
+	if err = batch.wait(); err != nil {
+		return nil, err
+	}
+	return batch.offsets, nil
+}
+
+// Appends the records the producer hasn't appended yet in a single Raft log entry
+// Every server's FSM checks the sequence numbers against the producers it has applied, so a retry that reaches a new
+// leader is still caught
+func (l *DistributedLog) AppendFrom(topic string, producer Producer, records []*api.Record) ([]uint64, error) {
+	now := time.Now().UnixNano()
+	for _, record := range records {
+		if record.Timestamp == 0 {
+			record.Timestamp = now
+		}
+		record.Checkpoint = nil
+	}
+	res, err := l.apply(
+		AppendBatchRequestType,
+		&api.ProduceBatchRequest{
+			Records:    records,
+			Topic:      topic,
+			ProducerId: producer.ID,
+			Sequence:   producer.Sequence,
+			AppendedAt: now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	batch := res.(*appendBatchResponse)
+	if err = batch.wait(); err != nil {
+		return nil, err
+	}
+	return batch.offsets, nil
 }
 
 // Tells Raft to apply the command, once there's a quorum and the command is committed
@@ -151,7 +259,7 @@ func (l *DistributedLog) apply(reqType RequestType, req proto.Marshaler) (
 	error,
 ) {
 	// Use a buffer here since we're supporting multiple request types:
-	// As of 07/02/2020, we're supporting only one request type, `append`
+	// e.g. `append` and `append batch`
 	//
 	// Write the request type identifier to the beginning of the buffer
 	var buf bytes.Buffer
@@ -174,11 +282,14 @@ func (l *DistributedLog) apply(reqType RequestType, req proto.Marshaler) (
 	// it replicates the record and appends the record to the leader's log
 	//
 	// This command must only be called on the cluster leader (as required by Raft)
-	future := l.raft.Apply(b, timeout)
+	future := l.raft.Apply(buf.Bytes(), timeout)
 	// an error indicates something went wrong with Raft's replication
 	// note: future.Error() is blocking
-	if future.Error() != nil {
-		return nil, future.Error()
+	if err := future.Error(); err == raft.ErrNotLeader {
+		// the command wasn't applied, so it's safe to send it to the leader instead
+		return nil, l.notLeader()
+	} else if err != nil {
+		return nil, err
 	}
 	res := future.Response()
 	if err, ok := res.(error); ok {
@@ -187,9 +298,128 @@ func (l *DistributedLog) apply(reqType RequestType, req proto.Marshaler) (
 	return res, nil
 }
 
-// Weak consistency guarantee
-func (l *DistributedLog) Read(offset uint64) (*api.Record, error) {
-	return l.log.Read(offset)
+// Weak consistency guarantee, reads whatever this server has applied so far
+// Call WaitForConsistency first for a stronger guarantee
+func (l *DistributedLog) Read(topic string, offset uint64) (*api.Record, error) {
+	return l.topics.Read(topic, offset)
+}
+
+// Weak consistency guarantee, like Read
+func (l *DistributedLog) OffsetForTime(topic string, t time.Time) (uint64, error) {
+	return l.topics.OffsetForTime(topic, t)
+}
+
+// Weak consistency guarantee, like Read
+func (l *DistributedLog) Iterator(topic string, from, to, maxBytes uint64) (*Iterator, error) {
+	return l.topics.Iterator(topic, from, to, maxBytes)
+}
+
+// Blocks until reads from this server meet the consistency level
+//
+// Anything stronger than stale can only be served by the leader, and returns api.ErrNotLeader naming the leader
+// on any other server
+func (l *DistributedLog) WaitForConsistency(c api.Consistency) error {
+	switch c {
+	case api.Consistency_STALE:
+		return nil
+	case api.Consistency_LEADER_LEASE:
+		// a leader steps down once it's lost contact with a quorum for its lease timeout, so while it's still the
+		// leader no other server can have acknowledged records it hasn't seen
+		if l.raft.State() != raft.Leader {
+			return l.notLeader()
+		}
+		return nil
+	case api.Consistency_LINEARIZABLE:
+		if l.raft.State() != raft.Leader {
+			return l.notLeader()
+		}
+		// the barrier is committed through the log like any command, so it fails if another leader's been elected,
+		// and it only returns once every command before it has been applied
+		err := l.raft.Barrier(10 * time.Second).Error()
+		if err == raft.ErrNotLeader || err == raft.ErrLeadershipLost {
+			return l.notLeader()
+		}
+		return err
+	}
+	return fmt.Errorf("unknown consistency level: %v", c)
+}
+
+// Returns the error for requests only the leader can serve, naming the leader so the client can go there instead
+func (l *DistributedLog) notLeader() error {
+	return api.ErrNotLeader{Leader: string(l.raft.Leader())}
+}
+
+// Creates the topic on every server through Raft
+func (l *DistributedLog) CreateTopic(name string) error {
+	_, err := l.apply(CreateTopicRequestType, &api.CreateTopicRequest{Name: name})
+	return err
+}
+
+// Deletes the topic and its records on every server through Raft
+func (l *DistributedLog) DeleteTopic(name string) error {
+	_, err := l.apply(DeleteTopicRequestType, &api.DeleteTopicRequest{Name: name})
+	return err
+}
+
+// Weak consistency guarantee, like Read
+func (l *DistributedLog) ListTopics() ([]string, error) {
+	return l.topics.ListTopics()
+}
+
+// Commits the offset through Raft, so every server has it should the leader fail
+func (l *DistributedLog) CommitOffset(topic, group string, offset uint64) error {
+	_, err := l.apply(
+		CommitOffsetRequestType,
+		&api.CommitOffsetRequest{Topic: topic, Group: group, Offset: offset},
+	)
+	return err
+}
+
+// Weak consistency guarantee like Read, call WaitForConsistency first for a stronger one
+func (l *DistributedLog) CommittedOffset(topic, group string) (uint64, error) {
+	return l.topics.CommittedOffset(topic, group)
+}
+
+func (l *DistributedLog) EndOffset(topic string) (uint64, error) {
+	return l.topics.EndOffset(topic)
+}
+
+// Adds the ACL rule through Raft, so every server enforces it
+// The rule is checked before it's proposed, once it's committed every server keeps it
+func (l *DistributedLog) AddRule(rule []string) error {
+	if l.config.Raft.Policy == nil {
+		return errNoPolicy
+	}
+	if err := l.config.Raft.Policy.validate(rule); err != nil {
+		return err
+	}
+	_, err := l.apply(AddRuleRequestType, &api.AddRuleRequest{Rule: newRule(rule)})
+	return err
+}
+
+// Removes an ACL rule added with AddRule
+func (l *DistributedLog) RemoveRule(rule []string) error {
+	if l.config.Raft.Policy == nil {
+		return errNoPolicy
+	}
+	_, err := l.apply(RemoveRuleRequestType, &api.RemoveRuleRequest{Rule: newRule(rule)})
+	return err
+}
+
+// Returns the ACL rules added through Raft
+func (l *DistributedLog) Rules() [][]string {
+	if l.config.Raft.Policy == nil {
+		return nil
+	}
+	return l.config.Raft.Policy.Rules()
+}
+
+// Returns the rule as sent through Raft
+func newRule(rule []string) *api.Rule {
+	if len(rule) == 0 {
+		return &api.Rule{}
+	}
+	return &api.Rule{Type: rule[0], Values: rule[1:]}
 }
 
 // Adds the server to the Raft cluster
@@ -246,12 +476,75 @@ func (l *DistributedLog) WaitForLeader(timeout time.Duration) error {
 	}
 }
 
+// Writes a checkpoint to each topic every interval while this server is the leader, until the log is closed
+func (l *DistributedLog) checkpointLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if l.raft.State() != raft.Leader {
+				continue
+			}
+			topics, _ := l.topics.ListTopics()
+			for _, topic := range topics {
+				if err := l.Checkpoint(topic); err != nil {
+					l.config.Logger.Error("writing checkpoint", "topic", topic, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// Checkpoint vouches for the records the leader has applied to the topic so far with a checkpoint signed by its key
+//
+// The leader signs the checkpoint and replicates it like any other record, so every server stores the same one.
+// Nothing is written if the last record is already a checkpoint.
+func (l *DistributedLog) Checkpoint(topic string) error {
+	log, err := l.topics.Log(topic)
+	if err != nil {
+		return err
+	}
+	log.mu.RLock()
+	record, err := log.checkpointRecord()
+	log.mu.RUnlock()
+	if err != nil || record == nil {
+		return err
+	}
+	record.Timestamp = time.Now().UnixNano()
+	res, err := l.apply(
+		AppendRequestType,
+		&api.ProduceRequest{Record: record, Topic: topic},
+	)
+	if err != nil {
+		return err
+	}
+	return res.(*appendResponse).wait()
+}
+
+// Verify recomputes the hash chain over this server's copy of the topic, see Log.Verify
+func (l *DistributedLog) Verify(topic string, from, to uint64) (Verification, error) {
+	log, err := l.topics.Log(topic)
+	if err != nil {
+		return Verification{}, err
+	}
+	return log.Verify(from, to)
+}
+
 func (l *DistributedLog) Close() error {
+	if l.stop != nil {
+		close(l.stop)
+		l.background.Wait()
+		l.stop = nil
+	}
 	f := l.raft.Shutdown()
 	if err := f.Error(); err != nil {
 		return err
 	}
-	return l.log.Close()
+	return l.topics.Close()
 }
 
 func (l *DistributedLog) GetServers() ([]*api.Server, error) {
@@ -276,7 +569,9 @@ var _ raft.FSM = (*fsm)(nil)
 
 // Raft runs our business logic through the FSM using the Apply method
 type fsm struct {
-	log *Log
+	topics *Topics
+	// nil unless this partition replicates the ACL policy
+	policy *Policy
 }
 
 // Raft invokes this method after committing a log entry
@@ -286,61 +581,350 @@ func (l *fsm) Apply(record *raft.Log) interface{} {
 	switch reqType {
 	case AppendRequestType:
 		return l.applyAppend(buf[1:])
+	case AppendBatchRequestType:
+		return l.applyAppendBatch(buf[1:])
+	case CreateTopicRequestType:
+		return l.applyCreateTopic(buf[1:])
+	case DeleteTopicRequestType:
+		return l.applyDeleteTopic(buf[1:])
+	case CommitOffsetRequestType:
+		return l.applyCommitOffset(buf[1:])
+	case AddRuleRequestType:
+		return l.applyAddRule(buf[1:])
+	case RemoveRuleRequestType:
+		return l.applyRemoveRule(buf[1:])
 	}
 	return nil
 }
 
+// What the FSM hands back to the node that applied an append
+type appendResponse struct {
+	offset uint64
+	// blocks until the record meets the log's sync policy
+	wait func() error
+}
+
 // unmarshals the record and append it to our local log file
+//
+// We don't wait for the record to be synced here since Raft applies commands one at a time, and waiting would stop
+// appends from sharing a group commit. The leader waits for it before acknowledging the record instead.
 func (l *fsm) applyAppend(b []byte) interface{} {
 	var req api.ProduceRequest
 	err := req.Unmarshal(b)
 	if err != nil {
 		return err
 	}
-	offset, err := l.log.Append(req.Record)
+	log, err := l.topics.Log(req.Topic)
+	if err != nil {
+		return err
+	}
+	offset, wait, err := log.append(req.Record)
+	if err != nil {
+		return err
+	}
+	return &appendResponse{offset: offset, wait: wait}
+}
+
+// What the FSM hands back to the node that applied a batch
+type appendBatchResponse struct {
+	offsets []uint64
+	wait    func() error
+}
+
+// unmarshals the records and appends all of them or none of them to our local log file
+// A batch from a producer skips the records the producer already appended
+func (l *fsm) applyAppendBatch(b []byte) interface{} {
+	var req api.ProduceBatchRequest
+	err := req.Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	if req.ProducerId != "" {
+		producer := Producer{ID: req.ProducerId, Sequence: req.Sequence}
+		now := req.AppendedAt
+		if now == 0 && len(req.Records) > 0 {
+			// entries proposed before the leader sent its clock went by the last record's timestamp
+			now = req.Records[len(req.Records)-1].Timestamp
+		}
+		offsets, wait, err := l.topics.appendFrom(req.Topic, producer, req.Records, now)
+		if err != nil {
+			return err
+		}
+		return &appendBatchResponse{offsets: offsets, wait: wait}
+	}
+	log, err := l.topics.Log(req.Topic)
+	if err != nil {
+		return err
+	}
+	offsets, wait, err := log.appendBatch(req.Records)
 	if err != nil {
 		return err
 	}
-	return &api.ProduceResponse{Offset: offset}
+	return &appendBatchResponse{offsets: offsets, wait: wait}
+}
+
+func (l *fsm) applyCreateTopic(b []byte) interface{} {
+	var req api.CreateTopicRequest
+	if err := req.Unmarshal(b); err != nil {
+		return err
+	}
+	if err := l.topics.CreateTopic(req.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *fsm) applyDeleteTopic(b []byte) interface{} {
+	var req api.DeleteTopicRequest
+	if err := req.Unmarshal(b); err != nil {
+		return err
+	}
+	if err := l.topics.DeleteTopic(req.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *fsm) applyCommitOffset(b []byte) interface{} {
+	var req api.CommitOffsetRequest
+	if err := req.Unmarshal(b); err != nil {
+		return err
+	}
+	if err := l.topics.CommitOffset(req.Topic, req.Group, req.Offset); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *fsm) applyAddRule(b []byte) interface{} {
+	var req api.AddRuleRequest
+	if err := req.Unmarshal(b); err != nil {
+		return err
+	}
+	if l.policy == nil {
+		return errNoPolicy
+	}
+	l.reportPolicy(l.policy.add(ruleValues(req.Rule)))
+	return nil
+}
+
+func (l *fsm) applyRemoveRule(b []byte) interface{} {
+	var req api.RemoveRuleRequest
+	if err := req.Unmarshal(b); err != nil {
+		return err
+	}
+	if l.policy == nil {
+		return errNoPolicy
+	}
+	err := l.policy.remove(ruleValues(req.Rule))
+	if _, ok := err.(api.ErrRuleNotFound); ok {
+		return err
+	}
+	l.reportPolicy(err)
+	return nil
+}
+
+// Rule changes are kept even when this server can't enforce them, so it holds the same rules as every other server
+func (l *fsm) reportPolicy(err error) {
+	if err != nil {
+		l.topics.Config.Logger.Error("enforcing replicated ACL rules", "error", err)
+	}
+}
+
+// Returns the rule as a line of the policy file, led by its type
+func ruleValues(rule *api.Rule) []string {
+	if rule == nil {
+		return nil
+	}
+	return append([]string{rule.Type}, rule.Values...)
 }
 
 // Called periodically to snapshot its state
-// Here, we are storing a snapshot of the entire log
+// Here, we are storing a snapshot of every topic's entire log
 func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
-	r := f.log.Reader()
-	return &snapshot{reader: r}, nil
+	names, err := f.topics.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+	topics := make([]snapshotTopic, 0, len(names))
+	for _, name := range names {
+		log, err := f.topics.Log(name)
+		if err != nil {
+			return nil, err
+		}
+		// Raft doesn't apply commands while it takes the snapshot, but it does while the snapshot's persisted, so stop
+		// at the records applied so far
+		log.mu.RLock()
+		end := log.activeSegment.nextOffset
+		log.mu.RUnlock()
+		lowest, err := log.LowestOffset()
+		if err != nil {
+			return nil, err
+		}
+		producers, err := f.topics.snapshotProducers(name)
+		if err != nil {
+			return nil, err
+		}
+		offsets, err := f.topics.snapshotOffsets(name)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, snapshotTopic{
+			name:      name,
+			lowest:    lowest,
+			end:       end,
+			producers: producers,
+			offsets:   offsets,
+			records:   log.Iterator(0, end, 0),
+		})
+	}
+	var policy []byte
+	if f.policy != nil {
+		if policy, err = f.policy.snapshot(); err != nil {
+			return nil, err
+		}
+	}
+	return &snapshot{
+		topics:  topics,
+		policy:  policy,
+		codec:   f.topics.Config.Segment.Codec,
+		keyring: f.topics.Config.Encryption.Keyring,
+	}, nil
 }
 
 // Raft calls this to restore an FSM from a snapshot
-func (f *fsm) Restore(r io.ReadCloser) error {
-	// reset the log
-	if err := f.log.Reset(); err != nil {
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	// reset the topics
+	if err := f.topics.Reset(); err != nil {
+		return err
+	}
+	if f.policy != nil {
+		f.reportPolicy(f.policy.replace(nil))
+	}
+	keyring := f.topics.Config.Encryption.Keyring
+	or, err := newOpenReader(rc, keyring)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(or)
+	// snapshots taken before there were topics are just the records, which go to the default topic
+	topics := true
+	magic, err := r.Peek(len(snapshotMagic))
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
 		return err
 	}
-	recordLength := make([]byte, lenWidth)
+	if string(magic) == snapshotMagic {
+		_, _ = r.Discard(len(snapshotMagic))
+	} else {
+		topics = false
+	}
+
+	name := DefaultTopic
+	log, err := f.topics.Log(name)
+	if err != nil {
+		return err
+	}
+	// the offset the topic's log ended at, once its bounds are known, after which records keep their offsets
+	var end uint64
+	bounded := false
+	finish := func() error {
+		if !bounded {
+			return nil
+		}
+		bounded = false
+		return log.skipTo(end)
+	}
+	kind := []byte{snapshotRecord}
+	header := make([]byte, headerWidth)
 	var buf bytes.Buffer
 	for { // loop til we hit End of File (io.EOF)
-		// Read the record's size
-		_, err := io.ReadFull(r, recordLength)
-		if err == io.EOF {
+		if topics {
+			if _, err := io.ReadFull(r, kind); err == io.EOF {
+				if err = finish(); err != nil {
+					return err
+				}
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+		// Read the entry's size and checksum
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF && !topics {
 			break
 		} else if err != nil {
 			return err
 		}
 
 		// copy the contents into buf
-		size := int64(enc.Uint64(recordLength))
+		size := int64(enc.Uint64(header[:lenWidth]))
 		if _, err = io.CopyN(&buf, r, size); err != nil {
 			return err
 		}
-
-		// append the record to the current log
-		record := &api.Record{}
-		if err = record.Unmarshal(buf.Bytes()); err != nil {
-			return err
+		// refuse to restore from a snapshot that was damaged in transit or at rest
+		if checksum(buf.Bytes()) != enc.Uint32(header[lenWidth:]) {
+			return errCorrupt
 		}
-		if _, err = f.log.Append(record); err != nil {
-			return err
+
+		switch kind[0] {
+		case snapshotTopicName:
+			if err = finish(); err != nil {
+				return err
+			}
+			// the records that follow belong to this topic
+			name = buf.String()
+			if name != DefaultTopic {
+				if err = f.topics.CreateTopic(name); err != nil {
+					return err
+				}
+			}
+			if log, err = f.topics.Log(name); err != nil {
+				return err
+			}
+		case snapshotBounds:
+			if buf.Len() != snapshotBoundsWidth {
+				return errCorrupt
+			}
+			// start the log where the one the snapshot was taken from started, so every record keeps its offset
+			if err = log.resetAt(enc.Uint64(buf.Bytes()[:8])); err != nil {
+				return err
+			}
+			end, bounded = enc.Uint64(buf.Bytes()[8:]), true
+		case snapshotProducers:
+			if err = f.topics.restoreProducers(name, buf.Bytes()); err != nil {
+				return err
+			}
+		case snapshotOffsets:
+			if err = f.topics.restoreOffsets(name, buf.Bytes()); err != nil {
+				return err
+			}
+		case snapshotPolicy:
+			if f.policy != nil {
+				rules, err := decodeRules(buf.Bytes())
+				if err != nil {
+					return err
+				}
+				f.reportPolicy(f.policy.replace(rules))
+			}
+		case snapshotRecord:
+			// append the record to the topic's log
+			record, err := decodeRecord(buf.Bytes(), keyring)
+			if err != nil {
+				return err
+			}
+			if bounded {
+				err = log.appendAt(record)
+			} else {
+				// snapshots taken before they had bounds are renumbered from the start of the log
+				_, err = log.Append(record)
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			return errCorrupt
 		}
 
 		buf.Reset()
@@ -348,6 +932,24 @@ func (f *fsm) Restore(r io.ReadCloser) error {
 	return nil
 }
 
+// Snapshots start with this, after the encryption flag, followed by the ACL rules added through Raft if the snapshot
+// has them, then each topic's name, its bounds, its producers and committed offsets if it has any, and then its
+// records, each holding its own offset
+const snapshotMagic = "LDGTOPICS1"
+
+// Each entry in a snapshot is one of these followed by a framed topic name, record, the topic's bounds as its lowest
+// offset and the offset after its last record, or the topic's producers, committed offsets or the ACL rules as JSON
+const (
+	snapshotTopicName byte = 0
+	snapshotRecord    byte = 1
+	snapshotProducers byte = 2
+	snapshotOffsets   byte = 3
+	snapshotPolicy    byte = 4
+	snapshotBounds    byte = 5
+
+	snapshotBoundsWidth = 8 + 8
+)
+
 var _ raft.FSMSnapshot = (*snapshot)(nil)
 
 // Point-in-time snapshot of the FSM's state
@@ -356,11 +958,73 @@ var _ raft.FSMSnapshot = (*snapshot)(nil)
 // The store could be in-memory, a file, or cloud storage (S3, GCS, etc...)
 // Here, we're using a file store
 type snapshot struct {
-	reader io.Reader
+	topics []snapshotTopic
+	// the ACL rules added through Raft, nil unless this partition replicates them
+	policy []byte
+	// records are framed the same way as in a store and compressed with the log's codec
+	codec Codec
+	// encrypts the snapshot at rest, nil leaves it unencrypted
+	keyring *Keyring
+}
+
+// A topic's records up to where the snapshot was taken, and its producers and committed offsets at that point
+type snapshotTopic struct {
+	name string
+	// the log's lowest offset and the offset after its last record, which compaction may have removed
+	lowest    uint64
+	end       uint64
+	producers []byte
+	offsets   []byte
+	records   *Iterator
 }
 
 func (s *snapshot) Persist(sink raft.SnapshotSink) error {
-	if _, err := io.Copy(sink, s.reader); err != nil {
+	w, err := newSealWriter(sink, s.keyring)
+	write := func(kind byte, p []byte) {
+		if err == nil {
+			_, err = w.Write([]byte{kind})
+		}
+		if err == nil {
+			_, err = w.Write(frameHeader(p))
+		}
+		if err == nil {
+			_, err = w.Write(p)
+		}
+	}
+	if err == nil {
+		_, err = w.Write([]byte(snapshotMagic))
+	}
+	if s.policy != nil {
+		write(snapshotPolicy, s.policy)
+	}
+	for _, topic := range s.topics {
+		write(snapshotTopicName, []byte(topic.name))
+		bounds := make([]byte, snapshotBoundsWidth)
+		enc.PutUint64(bounds[:8], topic.lowest)
+		enc.PutUint64(bounds[8:], topic.end)
+		write(snapshotBounds, bounds)
+		if topic.producers != nil {
+			write(snapshotProducers, topic.producers)
+		}
+		if topic.offsets != nil {
+			write(snapshotOffsets, topic.offsets)
+		}
+		for err == nil && topic.records.Next() {
+			// the whole snapshot is sealed, so there's no need to encrypt each record too
+			var p []byte
+			if p, err = encodeRecord(topic.records.Record(), s.codec, nil); err != nil {
+				break
+			}
+			write(snapshotRecord, p)
+		}
+		if err == nil {
+			err = topic.records.Err()
+		}
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
 		_ = sink.Cancel()
 		return err
 	}
@@ -382,6 +1046,7 @@ func newLogStore(dir string, c Config) (*logStore, error) {
 	if err != nil {
 		return nil, err
 	}
+	log.reportRecovery()
 	return &logStore{log}, nil
 }
 
@@ -409,18 +1074,25 @@ func (l *logStore) GetLog(index uint64, out *raft.Log) error {
 func (l *logStore) StoreLog(record *raft.Log) error {
 	return l.StoreLogs([]*raft.Log{record})
 }
+
+// Raft already hands us entries in batches, so rather than group committing across calls we make the whole batch
+// durable at once before returning, which is what lets Raft count this node towards a commit
 func (l *logStore) StoreLogs(records []*raft.Log) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	for _, record := range records {
-		if _, err := l.Append(&api.Record{
+		if _, err := l.write(&api.Record{
 			Value: record.Data,
 			Term:  record.Term,
 			Type:  uint32(record.Type),
-		});
-			err != nil {
+		}); err != nil {
 			return err
 		}
 	}
-	return nil
+	if l.Config.Segment.Sync == SyncNone {
+		return l.activeSegment.store.Flush()
+	}
+	return l.activeSegment.Sync()
 }
 
 func (l *logStore) DeleteRange(min, max uint64) error {
@@ -430,11 +1102,19 @@ func (l *logStore) DeleteRange(min, max uint64) error {
 var _ raft.StreamLayer = (*StreamLayer)(nil)
 
 // StreamLayer used by Raft to connect Raft servers
+//
+// Every partition's Raft group shares the same listener, each connection starts by naming the partition it's for
 type StreamLayer struct {
-	ln net.Listener
 	// we enable encrypted communication between servers with TLS, so we need the TLS configs
 	serverTLSConfig *tls.Config
 	peerTLSConfig   *tls.Config
+	// the Raft group this layer carries connections for
+	partition uint32
+	// hands each accepted connection to its partition's layer
+	demux *raftDemux
+	// closed once Raft's done with the layer
+	closed    chan struct{}
+	closeOnce *sync.Once
 }
 
 func NewStreamLayer(
@@ -443,9 +1123,27 @@ func NewStreamLayer(
 	peerTLSConfig *tls.Config,
 ) *StreamLayer {
 	return &StreamLayer{
-		ln:              ln,
 		serverTLSConfig: serverTLSConfig,
 		peerTLSConfig:   peerTLSConfig,
+		demux: &raftDemux{
+			ln:     ln,
+			conns:  make(map[uint32]chan net.Conn),
+			closed: make(chan struct{}),
+		},
+		closed:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+}
+
+// Returns a layer for the partition's Raft group, sharing this layer's listener
+func (s *StreamLayer) Partition(partition uint32) *StreamLayer {
+	return &StreamLayer{
+		serverTLSConfig: s.serverTLSConfig,
+		peerTLSConfig:   s.peerTLSConfig,
+		partition:       partition,
+		demux:           s.demux,
+		closed:          make(chan struct{}),
+		closeOnce:       &sync.Once{},
 	}
 }
 
@@ -457,7 +1155,14 @@ func (s *StreamLayer) Dial(addr raft.ServerAddress, timeout time.Duration) (net.
 		return nil, err
 	}
 	// write the identifier as the first byte to tell mux this is a raft rpc
-	_, err = conn.Write([]byte{byte(RaftRPC)})
+	// the first partition's connections are just the identifier, as they were before there were partitions
+	id := []byte{byte(RaftRPC)}
+	if s.partition != 0 {
+		id = make([]byte, 5)
+		id[0] = byte(RaftPartitionRPC)
+		enc.PutUint32(id[1:], s.partition)
+	}
+	_, err = conn.Write(id)
 	if err != nil {
 		return nil, err
 	}
@@ -468,29 +1173,125 @@ func (s *StreamLayer) Dial(addr raft.ServerAddress, timeout time.Duration) (net.
 }
 
 func (s *StreamLayer) Accept() (net.Conn, error) {
-	conn, err := s.ln.Accept()
-	if err != nil {
-		return nil, err
-	}
-	b := make([]byte, 1)
-	_, err = conn.Read(b)
+	conn, err := s.demux.accept(s.partition, s.closed)
 	if err != nil {
 		return nil, err
 	}
-	// identify that this is a Raft connection
-	if bytes.Compare([]byte{byte(RaftRPC)}, b) != 0 {
-		return nil, fmt.Errorf("not a raft rpc")
-	}
 	if s.serverTLSConfig != nil {
 		return tls.Server(conn, s.serverTLSConfig), nil
 	}
 	return conn, nil
 }
 
+// Closing the first partition's layer closes the shared listener too, so close it last
 func (s *StreamLayer) Close() error {
-	return s.ln.Close()
+	s.closeOnce.Do(func() { close(s.closed) })
+	if s.partition != 0 {
+		return nil
+	}
+	return s.demux.close()
 }
 
 func (s *StreamLayer) Addr() net.Addr {
-	return s.ln.Addr()
+	return s.demux.ln.Addr()
+}
+
+var errStreamLayerClosed = errors.New("raft: stream layer closed")
+
+// Accepts connections on the listener shared by every partition and hands each to its partition once it's read which
+// partition the connection is for
+type raftDemux struct {
+	ln    net.Listener
+	start sync.Once
+	mu    sync.Mutex
+	// accepted connections waiting for their partition's layer, by partition
+	conns map[uint32]chan net.Conn
+	// closed once the listener fails, err says why
+	closed    chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+func (d *raftDemux) accept(partition uint32, closed chan struct{}) (net.Conn, error) {
+	d.start.Do(func() { go d.serve() })
+	select {
+	case conn := <-d.queue(partition):
+		return conn, nil
+	case <-d.closed:
+		return nil, d.err
+	case <-closed:
+		return nil, errStreamLayerClosed
+	}
+}
+
+// Returns the partition's queue of accepted connections
+func (d *raftDemux) queue(partition uint32) chan net.Conn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	q, ok := d.conns[partition]
+	if !ok {
+		q = make(chan net.Conn)
+		d.conns[partition] = q
+	}
+	return q
+}
+
+func (d *raftDemux) serve() {
+	for {
+		conn, err := d.ln.Accept()
+		if err != nil {
+			d.closeOnce.Do(func() {
+				d.err = err
+				close(d.closed)
+			})
+			return
+		}
+		// read the identifier in the background so a slow client doesn't hold up the others
+		go d.route(conn)
+	}
+}
+
+func (d *raftDemux) route(conn net.Conn) {
+	partition, err := readPartition(conn)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	select {
+	case d.queue(partition) <- conn:
+	case <-d.closed:
+		conn.Close()
+	case <-time.After(10 * time.Second):
+		// nothing's accepting the partition's connections, e.g. the peer was started with more partitions
+		conn.Close()
+	}
+}
+
+// Reads the identifier a Raft connection starts with and returns the partition it's for
+func readPartition(conn net.Conn) (uint32, error) {
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		return 0, err
+	}
+	// identify that this is a Raft connection
+	switch b[0] {
+	case byte(RaftRPC):
+		return 0, nil
+	case byte(RaftPartitionRPC):
+		b = make([]byte, 4)
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return 0, err
+		}
+		return enc.Uint32(b), nil
+	}
+	return 0, fmt.Errorf("not a raft rpc")
+}
+
+func (d *raftDemux) close() error {
+	err := d.ln.Close()
+	d.closeOnce.Do(func() {
+		d.err = errStreamLayerClosed
+		close(d.closed)
+	})
+	return err
 }
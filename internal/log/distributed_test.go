@@ -1,8 +1,12 @@
 package log_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net"
 	"os"
 	"reflect"
@@ -42,6 +46,18 @@ func TestMultipleNodes(t *testing.T) {
 		config.Raft.ElectionTimeout = 50 * time.Millisecond
 		config.Raft.LeaderLeaseTimeout = 50 * time.Millisecond
 		config.Raft.CommitTimeout = 5 * time.Millisecond
+		config.Raft.Policy = log.NewPolicy(func(rule []string) error {
+			if rule[0] != "p" && rule[0] != "g" {
+				return errors.New("invalid rule")
+			}
+			return nil
+		}, func(rules [][]string) error {
+			// one server that can't enforce the rules still keeps them
+			if config.Raft.LocalID == "2" {
+				return errors.New("can't enforce rules")
+			}
+			return nil
+		})
 
 		if i == 0 {
 			config.Raft.Bootstrap = true
@@ -68,12 +84,12 @@ func TestMultipleNodes(t *testing.T) {
 		{Value: []byte("second")},
 	}
 	for _, record := range records {
-		off, err := logs[0].Append(record)
+		off, err := logs[0].Append(log.DefaultTopic, record)
 		require.NoError(t, err)
 
 		require.Eventually(t, func() bool {
 			for j := 0; j < nodeCount; j++ {
-				got, err := logs[j].Read(off)
+				got, err := logs[j].Read(log.DefaultTopic, off)
 				if err != nil {
 					return false
 				}
@@ -86,6 +102,96 @@ func TestMultipleNodes(t *testing.T) {
 		}, 500*time.Millisecond, 50*time.Millisecond)
 	}
 
+	// a batch is replicated as a single entry and applied in one go
+	batch := []*api.Record{
+		{Value: []byte("batch-0")},
+		{Value: []byte("batch-1")},
+		{Value: []byte("batch-2")},
+	}
+	offsets, err := logs[0].AppendBatch(log.DefaultTopic, batch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3, 4}, offsets)
+	require.Eventually(t, func() bool {
+		for j := 0; j < nodeCount; j++ {
+			for i, off := range offsets {
+				got, err := logs[j].Read(log.DefaultTopic, off)
+				if err != nil || string(got.Value) != string(batch[i].Value) {
+					return false
+				}
+			}
+		}
+		return true
+	}, 500*time.Millisecond, 50*time.Millisecond)
+
+	// every server remembers the producer, so a retry isn't appended again
+	producer := log.Producer{ID: "checkout", Sequence: 7}
+	for i := 0; i < 2; i++ {
+		offsets, err = logs[0].AppendFrom(log.DefaultTopic, producer, []*api.Record{{Value: []byte("payment")}})
+		require.NoError(t, err)
+		require.Equal(t, []uint64{5}, offsets)
+	}
+	require.Eventually(t, func() bool {
+		_, err := logs[1].Read(log.DefaultTopic, 5)
+		return err == nil
+	}, 500*time.Millisecond, 50*time.Millisecond)
+	_, err = logs[1].Read(log.DefaultTopic, 6)
+	require.IsType(t, api.ErrOffsetOutOfRange{}, err)
+
+	// committed offsets are replicated too
+	require.NoError(t, logs[0].CommitOffset(log.DefaultTopic, "projection", 5))
+	require.Eventually(t, func() bool {
+		offset, err := logs[2].CommittedOffset(log.DefaultTopic, "projection")
+		return err == nil && offset == 5
+	}, 500*time.Millisecond, 50*time.Millisecond)
+	require.IsType(t, api.ErrNotLeader{}, logs[1].CommitOffset(log.DefaultTopic, "projection", 6))
+
+	// and so are ACL rules
+	rule := []string{"g", "projection", "reader"}
+	require.NoError(t, logs[0].AddRule(rule))
+	require.Eventually(t, func() bool {
+		return len(logs[2].Rules()) == 1
+	}, 500*time.Millisecond, 50*time.Millisecond)
+	require.Equal(t, [][]string{rule}, logs[2].Rules())
+	// the leader checks rules before proposing them
+	require.Error(t, logs[0].AddRule([]string{"x", "projection", "reader"}))
+	require.Equal(t, [][]string{rule}, logs[0].Rules())
+	require.IsType(t, api.ErrNotLeader{}, logs[1].RemoveRule(rule))
+	require.Equal(t, api.ErrRuleNotFound{Rule: []string{"g", "nobody", "reader"}},
+		logs[0].RemoveRule([]string{"g", "nobody", "reader"}))
+
+	// only the leader serves consistent reads, followers name the leader instead
+	leader := fmt.Sprintf("127.0.0.1:%d", ports[0])
+	for _, c := range []api.Consistency{api.Consistency_LEADER_LEASE, api.Consistency_LINEARIZABLE} {
+		require.NoError(t, logs[0].WaitForConsistency(c))
+		require.Equal(t, api.ErrNotLeader{Leader: leader}, logs[1].WaitForConsistency(c))
+	}
+	require.NoError(t, logs[1].WaitForConsistency(api.Consistency_STALE))
+
+	// topics are created and deleted on every server, and each has offsets of its own
+	require.NoError(t, logs[0].CreateTopic("payments"))
+	require.IsType(t, api.ErrTopicExists{}, logs[0].CreateTopic("payments"))
+	off, err := logs[0].Append("payments", &api.Record{Value: []byte("payment")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), off)
+	require.Eventually(t, func() bool {
+		for j := 0; j < nodeCount; j++ {
+			got, err := logs[j].Read("payments", off)
+			if err != nil || string(got.Value) != "payment" {
+				return false
+			}
+		}
+		return true
+	}, 500*time.Millisecond, 50*time.Millisecond)
+	require.NoError(t, logs[0].DeleteTopic("payments"))
+	require.Eventually(t, func() bool {
+		for j := 0; j < nodeCount; j++ {
+			if topics, _ := logs[j].ListTopics(); !reflect.DeepEqual(topics, []string{log.DefaultTopic}) {
+				return false
+			}
+		}
+		return true
+	}, 500*time.Millisecond, 50*time.Millisecond)
+
 	servers, err := logs[0].GetServers()
 	require.NoError(t, err)
 	require.Equal(t, 3, len(servers))
@@ -104,21 +210,174 @@ func TestMultipleNodes(t *testing.T) {
 	require.True(t, servers[0].IsLeader)
 	require.False(t, servers[1].IsLeader)
 
-	off, err := logs[0].Append(&api.Record{
+	third := &api.Record{
 		Value: []byte("third"),
-	})
+	}
+	off, err = logs[0].Append(log.DefaultTopic, third)
 	require.NoError(t, err)
 
 	time.Sleep(50 * time.Millisecond)
 
-	record, err := logs[1].Read(off)
+	record, err := logs[1].Read(log.DefaultTopic, off)
 	require.IsType(t, api.ErrOffsetOutOfRange{}, err)
 	require.Nil(t, record)
 
-	record, err = logs[2].Read(off)
+	record, err = logs[2].Read(log.DefaultTopic, off)
 	require.NoError(t, err)
 	require.Equal(t, &api.Record{
-		Value:  []byte("third"),
-		Offset: off,
+		Value:     []byte("third"),
+		Offset:    off,
+		Timestamp: third.Timestamp,
 	}, record)
 }
+
+func TestDistributedCheckpoints(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var logs []*log.DistributedLog
+	nodeCount := 2
+	ports := dynaport.Get(nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		dataDir, err := ioutil.TempDir("", "distributed-log-test")
+		require.NoError(t, err)
+		defer os.RemoveAll(dataDir)
+
+		ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", ports[i]))
+		require.NoError(t, err)
+
+		config := log.Config{}
+		config.Raft.StreamLayer = log.NewStreamLayer(ln, nil, nil)
+		config.Raft.LocalID = raft.ServerID(fmt.Sprintf("%d", i))
+		config.Raft.HeartbeatTimeout = 50 * time.Millisecond
+		config.Raft.ElectionTimeout = 50 * time.Millisecond
+		config.Raft.LeaderLeaseTimeout = 50 * time.Millisecond
+		config.Raft.CommitTimeout = 5 * time.Millisecond
+		config.Chain.Enabled = true
+		config.Chain.VerifyKeys = map[string]ed25519.PublicKey{"0": pub}
+		if i == 0 {
+			config.Raft.Bootstrap = true
+			config.Chain.SigningKey = key
+			config.Chain.SigningKeyID = "0"
+			// written explicitly below
+			config.Chain.CheckpointInterval = time.Hour
+		}
+
+		l, err := log.NewDistributedLog(dataDir, config)
+		require.NoError(t, err)
+		defer l.Close()
+		if i == 0 {
+			require.NoError(t, l.WaitForLeader(3*time.Second))
+		} else {
+			require.NoError(t, logs[0].Join(fmt.Sprintf("%d", i), ln.Addr().String()))
+		}
+		logs = append(logs, l)
+	}
+
+	for i := 0; i < 5; i++ {
+		_, err := logs[0].Append(log.DefaultTopic, &api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+	require.NoError(t, logs[0].Checkpoint(log.DefaultTopic))
+
+	// the follower stores the same chain, so the leader's checkpoint holds on it too
+	require.Eventually(t, func() bool {
+		v, err := logs[1].Verify(log.DefaultTopic, 0, math.MaxUint64)
+		return err == nil && v == log.Verification{Records: 6, Checkpoints: 1}
+	}, time.Second, 50*time.Millisecond)
+	for off := uint64(0); off < 6; off++ {
+		want, err := logs[0].Read(log.DefaultTopic, off)
+		require.NoError(t, err)
+		got, err := logs[1].Read(log.DefaultTopic, off)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
+
+func TestPartitionedLog(t *testing.T) {
+	var logs []*log.PartitionedLog
+	nodeCount := 3
+	ports := dynaport.Get(nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		dataDir, err := ioutil.TempDir("", "partitioned-log-test")
+		require.NoError(t, err)
+		defer os.RemoveAll(dataDir)
+
+		ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", ports[i]))
+		require.NoError(t, err)
+
+		config := log.Config{}
+		config.Raft.StreamLayer = log.NewStreamLayer(ln, nil, nil)
+		config.Raft.LocalID = raft.ServerID(fmt.Sprintf("%d", i))
+		config.Raft.HeartbeatTimeout = 50 * time.Millisecond
+		config.Raft.ElectionTimeout = 50 * time.Millisecond
+		config.Raft.LeaderLeaseTimeout = 50 * time.Millisecond
+		config.Raft.CommitTimeout = 5 * time.Millisecond
+		config.Raft.Bootstrap = i == 0
+		config.Raft.Partitions = uint32(nodeCount)
+		config.Raft.BalanceInterval = 100 * time.Millisecond
+
+		l, err := log.NewPartitionedLog(dataDir, config)
+		require.NoError(t, err)
+		defer l.Close()
+
+		if i == 0 {
+			require.NoError(t, l.WaitForLeader(3*time.Second))
+		}
+		// like membership, every server adds the new one to the partitions it leads
+		for _, other := range logs {
+			if err := other.Join(fmt.Sprintf("%d", i), ln.Addr().String()); err != raft.ErrNotLeader {
+				require.NoError(t, err)
+			}
+		}
+		logs = append(logs, l)
+	}
+
+	// each server ends up leading one partition
+	leaders := make(map[uint32]int)
+	require.Eventually(t, func() bool {
+		servers, err := logs[0].GetServers()
+		if err != nil || len(servers) != nodeCount {
+			return false
+		}
+		for i, server := range servers {
+			if len(server.LeaderOf) != 1 {
+				return false
+			}
+			leaders[server.LeaderOf[0]] = i
+		}
+		return len(leaders) == nodeCount
+	}, 5*time.Second, 100*time.Millisecond)
+
+	for p := uint32(0); p < uint32(nodeCount); p++ {
+		partition, err := logs[leaders[p]].Partition(p)
+		require.NoError(t, err)
+		value := []byte(fmt.Sprintf("partition-%d", p))
+		off, err := partition.Append(log.DefaultTopic, &api.Record{Value: value})
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), off)
+		require.Eventually(t, func() bool {
+			for _, l := range logs {
+				partition, err := l.Partition(p)
+				require.NoError(t, err)
+				got, err := partition.Read(log.DefaultTopic, off)
+				if err != nil || string(got.Value) != string(value) {
+					return false
+				}
+			}
+			return true
+		}, time.Second, 50*time.Millisecond)
+	}
+	_, err := logs[0].Partition(uint32(nodeCount))
+	require.Equal(t, api.ErrPartitionNotFound{Partition: uint32(nodeCount), Partitions: uint32(nodeCount)}, err)
+
+	// only the first partition replicates ACL rules, the others turn them away on every server without proposing them
+	rule := []string{"g", "projection", "reader"}
+	for _, l := range logs {
+		partition, err := l.Partition(1)
+		require.NoError(t, err)
+		distributed := partition.(*log.DistributedLog)
+		require.EqualError(t, distributed.AddRule(rule), "log: the policy is only replicated by the first partition")
+		require.EqualError(t, distributed.RemoveRule(rule), "log: the policy is only replicated by the first partition")
+	}
+}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"time"
+)
+
+// Appends covered by the same fsync when group committing
+type syncGroup struct {
+	// closed once the group's fsync has finished
+	done chan struct{}
+	err  error
+}
+
+func newSyncGroup() *syncGroup {
+	return &syncGroup{done: make(chan struct{})}
+}
+
+// Blocks until the group's fsync has finished
+func (g *syncGroup) wait() error {
+	<-g.done
+	return g.err
+}
+
+// Makes the n records appended since the last commit durable according to the log's sync policy
+//
+// Must be called with the log's lock held
+// Returns a function that blocks until the records are durable, which should be called after releasing the lock so
+// other appends can join the same group commit
+func (l *Log) commit(n uint64) func() error {
+	var err error
+	switch l.Config.Segment.Sync {
+	case SyncAlways:
+		err = l.activeSegment.Sync()
+	case SyncBatch:
+		g := l.group
+		l.pending += n
+		if every := l.Config.Segment.SyncEveryRecords; every > 0 && l.pending >= every {
+			l.syncGroup()
+		}
+		return g.wait
+	default:
+		// hand the records to the OS so a crash of the process alone can't lose them
+		err = l.activeSegment.store.Flush()
+	}
+	return func() error { return err }
+}
+
+// fsyncs the active segment and releases every append waiting on the current group
+// Must be called with the log's lock held
+func (l *Log) syncGroup() {
+	g := l.group
+	g.err = l.activeSegment.Sync()
+	close(g.done)
+	l.group = newSyncGroup()
+	l.pending = 0
+}
+
+// Called before we stop appending to the active segment so none of its records are left behind in memory, and its
+// time index covers its newest record
+// Must be called with the log's lock held
+func (l *Log) seal() error {
+	if err := l.activeSegment.timeIndex.Flush(); err != nil {
+		return err
+	}
+	if l.Config.Segment.Sync == SyncNone {
+		return l.activeSegment.store.Flush()
+	}
+	return l.activeSegment.Sync()
+}
+
+// Sync forces every appended record to disk, whatever the sync policy
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending > 0 {
+		g := l.group
+		l.syncGroup()
+		return g.err
+	}
+	return l.activeSegment.Sync()
+}
+
+// Group commits waiting appends every interval until the log is closed
+func (l *Log) syncLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			if l.pending > 0 {
+				l.syncGroup()
+			}
+			l.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package log
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var (
+	// returned when reading an encrypted record or snapshot without a key to decrypt it
+	errNoKey = errors.New("log: record is encrypted with a key that isn't in the keyring")
+)
+
+// Keyring holds the AES keys used to encrypt records at rest
+//
+// Every encrypted record carries the ID of the key it was encrypted with, so to rotate keys add a new key, make it
+// the active one, and keep the old ones around for as long as records encrypted with them are in the log
+//
+// Key IDs are kept with each record rather than in the segment's header. The active segment carries on being appended
+// to after a restart with a rotated keyring, so a segment can hold records sealed with both keys without having to
+// roll it early. Records are also sealed and read one at a time, by offset, so each one naming its key is what lets
+// a single record be decrypted without anything else from its segment. Compacting a segment or restoring a snapshot
+// rewrites its records with the active key. The cost is the ID's length plus a byte on each encrypted record.
+type Keyring struct {
+	// ID of the key new records are encrypted with
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// Creates a keyring from AES-128 or AES-256 keys by ID, encrypting new records with the active key
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{
+		active: active,
+		keys:   make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key id: %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", id, err)
+		}
+		if k.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q isn't in the keyring", active)
+	}
+	return k, nil
+}
+
+// Parses a keyring from "<id>:<base64 key>" entries separated by newlines or commas
+// The last entry is the active key unless active names another one
+func ParseKeyring(text, active string) (*Keyring, error) {
+	keys, last, err := parseKeys(text)
+	if err != nil {
+		return nil, err
+	}
+	if active == "" {
+		active = last
+	}
+	return NewKeyring(active, keys)
+}
+
+// Parses "<id>:<base64 key>" entries separated by newlines or commas, skipping blank lines and # comments
+// Also returns the ID of the last entry
+func parseKeys(text string) (map[string][]byte, string, error) {
+	keys := make(map[string][]byte)
+	var last string
+	for _, entry := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == ','
+	}) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, "", fmt.Errorf("invalid key entry, expected <id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, "", fmt.Errorf("key %q: %v", parts[0], err)
+		}
+		last = strings.TrimSpace(parts[0])
+		keys[last] = key
+	}
+	return keys, last, nil
+}
+
+// Loads a keyring from a key file, or from the environment variable if the file isn't set
+func LoadKeyring(file, env, active string) (*Keyring, error) {
+	var text string
+	if file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	} else {
+		text = os.Getenv(env)
+	}
+	return ParseKeyring(text, active)
+}
+
+// Encrypts b with the active key, prefixing it with the key's ID and the nonce
+// The additional data isn't stored, but has to be the same to open b again
+func (k *Keyring) seal(b, additional []byte) ([]byte, error) {
+	aead := k.keys[k.active]
+	out := make([]byte, 0, 1+len(k.active)+aead.NonceSize()+len(b)+aead.Overhead())
+	out = append(out, byte(len(k.active)))
+	out = append(out, k.active...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, b, additional), nil
+}
+
+// Decrypts b with the key it names
+// Returns errCorrupt if b was changed, or was sealed with other additional data
+func (k *Keyring) open(b, additional []byte) ([]byte, error) {
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return nil, errCorrupt
+	}
+	id := string(b[1 : 1+b[0]])
+	b = b[1+len(id):]
+	if k == nil {
+		return nil, errNoKey
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, errNoKey
+	}
+	if len(b) < aead.NonceSize() {
+		return nil, errCorrupt
+	}
+	b, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], additional)
+	if err != nil {
+		return nil, errCorrupt
+	}
+	return b, nil
+}
+
+// size of the chunks a snapshot is encrypted in
+const snapshotChunkSize = 64 * 1024
+
+// The flag a stream written by newSealWriter starts with
+const (
+	streamPlain byte = iota
+	// chunks sealed on their own, written before each chunk's position was sealed with it
+	streamChunks
+	// chunks sealed with their position in the stream and whether they're the last one
+	streamOrderedChunks
+)
+
+// Wraps w so everything written to it is encrypted with the keyring's active key, or passed through as is if
+// there's no keyring
+//
+// The stream starts with a flag saying whether it's encrypted, followed by sealed chunks. Each chunk is framed by a
+// byte saying whether it's the last chunk and its length. The chunk's number and that byte are sealed along with it,
+// so chunks that are reordered, dropped or cut off at the end fail to open.
+func newSealWriter(w io.Writer, k *Keyring) (io.WriteCloser, error) {
+	if k == nil {
+		_, err := w.Write([]byte{streamPlain})
+		return nopCloser{w}, err
+	}
+	if _, err := w.Write([]byte{streamOrderedChunks}); err != nil {
+		return nil, err
+	}
+	return &sealWriter{w: w, keyring: k}, nil
+}
+
+type sealWriter struct {
+	w       io.Writer
+	keyring *Keyring
+	// written but not sealed yet, the last chunk is only sealed once the stream's closed
+	buf []byte
+	// number of the next chunk
+	chunk uint64
+}
+
+func (s *sealWriter) Write(p []byte) (int, error) {
+	s.buf = append(s.buf, p...)
+	for len(s.buf) > snapshotChunkSize {
+		if err := s.seal(s.buf[:snapshotChunkSize], false); err != nil {
+			return 0, err
+		}
+		s.buf = append(s.buf[:0], s.buf[snapshotChunkSize:]...)
+	}
+	return len(p), nil
+}
+
+// Close seals whatever is left as the last chunk, it doesn't close the underlying writer
+func (s *sealWriter) Close() error {
+	err := s.seal(s.buf, true)
+	s.buf = nil
+	return err
+}
+
+func (s *sealWriter) seal(p []byte, last bool) error {
+	sealed, err := s.keyring.seal(p, chunkData(s.chunk, last))
+	if err != nil {
+		return err
+	}
+	s.chunk++
+	header := make([]byte, 1+4)
+	if last {
+		header[0] = 1
+	}
+	enc.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err = s.w.Write(header); err != nil {
+		return err
+	}
+	_, err = s.w.Write(sealed)
+	return err
+}
+
+// The data a chunk is sealed with besides its content: its number in the stream and whether it's the last one
+func chunkData(chunk uint64, last bool) []byte {
+	b := make([]byte, 8+1)
+	enc.PutUint64(b, chunk)
+	if last {
+		b[8] = 1
+	}
+	return b
+}
+
+// Reads a stream written by newSealWriter, decrypting it with the keyring if it's encrypted
+func newOpenReader(r io.Reader, k *Keyring) (io.Reader, error) {
+	flag := make([]byte, 1)
+	if _, err := io.ReadFull(r, flag); err != nil {
+		return nil, err
+	}
+	switch flag[0] {
+	case streamPlain:
+		return r, nil
+	case streamChunks:
+		return &openReader{r: r, keyring: k}, nil
+	case streamOrderedChunks:
+		return &openReader{r: r, keyring: k, ordered: true}, nil
+	}
+	return nil, fmt.Errorf("log: unknown stream encryption %d", flag[0])
+}
+
+type openReader struct {
+	r       io.Reader
+	keyring *Keyring
+	// whether each chunk was sealed with its number and whether it's the last one
+	ordered bool
+	// number of the next chunk, and whether we've read the last one
+	chunk uint64
+	done  bool
+	// what's left of the last chunk we decrypted
+	plain []byte
+}
+
+func (o *openReader) Read(p []byte) (int, error) {
+	for len(o.plain) == 0 {
+		if o.done {
+			return 0, io.EOF
+		}
+		if err := o.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, o.plain)
+	o.plain = o.plain[n:]
+	return n, nil
+}
+
+// Reads and decrypts the next chunk
+func (o *openReader) next() error {
+	var last bool
+	if o.ordered {
+		flag := make([]byte, 1)
+		if _, err := io.ReadFull(o.r, flag); err == io.EOF {
+			// the stream was cut off before its last chunk
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		last = flag[0] != 0
+	}
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(o.r, size); err != nil {
+		if err == io.EOF && o.ordered {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	sealed := make([]byte, enc.Uint32(size))
+	if _, err := io.ReadFull(o.r, sealed); err != nil {
+		return err
+	}
+	var additional []byte
+	if o.ordered {
+		additional = chunkData(o.chunk, last)
+	}
+	var err error
+	if o.plain, err = o.keyring.open(sealed, additional); err != nil {
+		return err
+	}
+	o.chunk++
+	o.done = last
+	return nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
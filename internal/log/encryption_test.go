@@ -0,0 +1,175 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+
+	api "ledger/api/v1"
+)
+
+func TestEncryption(t *testing.T) {
+	first, err := ParseKeyring("first:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)), "")
+	require.NoError(t, err)
+	rotated, err := ParseKeyring(
+		"first:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))+
+			",second:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16)),
+		"",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "second", rotated.active)
+
+	dir, err := ioutil.TempDir("", "encryption-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	value := []byte("payment of 100.00 from alice to bob")
+	c := Config{}
+	c.Encryption.Keyring = first
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	_, err = l.Append(&api.Record{Value: value})
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	// the value never hits the disk in the clear
+	b, err := ioutil.ReadFile(l.activeSegment.store.Name())
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(b, value))
+
+	// records written before a rotation stay readable
+	c.Encryption.Keyring = rotated
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	_, err = l.Append(&api.Record{Value: value})
+	require.NoError(t, err)
+	for off := uint64(0); off < 2; off++ {
+		record, err := l.Read(off)
+		require.NoError(t, err)
+		require.Equal(t, value, record.Value)
+	}
+
+	// snapshots are encrypted and restore into the same records
+	sink := &testSink{}
+	topics := &Topics{Config: c, logs: map[string]*Log{DefaultTopic: l}}
+	snap, err := (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+	require.False(t, bytes.Contains(sink.Bytes(), value))
+
+	restoreDir, err := ioutil.TempDir("", "encryption-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(restoreDir)
+	restored, err := NewTopics(restoreDir, c)
+	require.NoError(t, err)
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+	for off := uint64(0); off < 2; off++ {
+		record, err := restored.Read(DefaultTopic, off)
+		require.NoError(t, err)
+		require.Equal(t, value, record.Value)
+	}
+	require.NoError(t, restored.Close())
+	require.NoError(t, l.Close())
+
+	// without the key the records can't be read
+	c.Encryption.Keyring = nil
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	_, err = l.Read(0)
+	require.Equal(t, errNoKey, err)
+	require.NoError(t, l.Close())
+}
+
+func TestSealedStream(t *testing.T) {
+	keyring, err := ParseKeyring("first:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)), "")
+	require.NoError(t, err)
+	content := bytes.Repeat([]byte("payment of 100.00 from alice to bob\n"), 3*snapshotChunkSize/36+10)
+
+	var sealed bytes.Buffer
+	w, err := newSealWriter(&sealed, keyring)
+	require.NoError(t, err)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	read := func(b []byte) ([]byte, error) {
+		r, err := newOpenReader(bytes.NewReader(b), keyring)
+		require.NoError(t, err)
+		return ioutil.ReadAll(r)
+	}
+	b, err := read(sealed.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, content, b)
+
+	// split the stream into its chunks
+	var chunks [][]byte
+	for rest := sealed.Bytes()[1:]; len(rest) > 0; {
+		n := 1 + 4 + int(enc.Uint32(rest[1:5]))
+		chunks = append(chunks, rest[:n])
+		rest = rest[n:]
+	}
+	require.Equal(t, 4, len(chunks))
+	join := func(chunks ...[]byte) []byte {
+		return append([]byte{streamOrderedChunks}, bytes.Join(chunks, nil)...)
+	}
+
+	// chunks that were swapped fail to open
+	_, err = read(join(chunks[1], chunks[0], chunks[2], chunks[3]))
+	require.Equal(t, errCorrupt, err)
+	// as does a stream cut off after a whole chunk, whether or not its last chunk is marked as the last
+	_, err = read(join(chunks[0], chunks[1]))
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	marked := append([]byte{1}, chunks[1][1:]...)
+	_, err = read(join(chunks[0], marked))
+	require.Equal(t, errCorrupt, err)
+
+	// streams sealed before chunks were numbered are still read
+	legacy, err := keyring.seal(content, nil)
+	require.NoError(t, err)
+	size := make([]byte, 4)
+	enc.PutUint32(size, uint32(len(legacy)))
+	b, err = read(append(append([]byte{streamChunks}, size...), legacy...))
+	require.NoError(t, err)
+	require.Equal(t, content, b)
+
+	// a record that fails to decrypt is corrupt
+	dir, err := ioutil.TempDir("", "encryption-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c := Config{}
+	c.Encryption.Keyring = keyring
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	_, err = l.Append(&api.Record{Value: []byte("payment")})
+	require.NoError(t, err)
+	p, err := l.activeSegment.store.ReadAt(segmentHeaderWidth)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	// flip a bit of the ciphertext and reframe it, so it passes its checksum
+	p[len(p)-1] ^= 1
+	f, err := os.OpenFile(l.activeSegment.store.Name(), os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteAt(append(frameHeader(p), p...), segmentHeaderWidth)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	defer l.Close()
+	_, err = l.Read(0)
+	require.Equal(t, api.ErrCorruptRecord{Offset: 0}, err)
+}
+
+type testSink struct {
+	bytes.Buffer
+}
+
+func (s *testSink) ID() string    { return "test" }
+func (s *testSink) Cancel() error { return nil }
+func (s *testSink) Close() error  { return nil }
+
+var _ raft.SnapshotSink = (*testSink)(nil)
@@ -0,0 +1,260 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	api "ledger/api/v1"
+)
+
+// Every segment's store starts with a header saying which format the segment is written in, so the format can change
+// without breaking existing logs
+//
+// Header layout: magic (4 bytes), version (1), codec (1), base offset (8), creation time in unix nanoseconds (8)
+const (
+	segmentMagic = "LDGR"
+	// segments written before stores had headers, with 12-byte index entries holding uint32 relative offsets
+	// The first of these framed each record as its 8-byte length followed by the marshaled record, later ones added
+	// a checksum to the frame and attributes to the record
+	segmentVersion1 = 1
+	// stores start with a header, and index and time index entries hold uint64 relative offsets
+	segmentVersion2 = 2
+	// a frame in the store may hold a batch of records, compressed and encrypted together
+	segmentVersion3 = 3
+	// the version new segments are written in
+	segmentVersion = segmentVersion3
+
+	segmentHeaderWidth = 4 + 1 + 1 + 8 + 8
+	// width of a version 1 index entry
+	legacyEntWidth = 4 + 8
+	// holds the new segment while an old one is being upgraded
+	upgradingDir = "upgrading"
+)
+
+var errNoHeader = errors.New("log: segment has no header")
+
+// Describes a segment, at the start of its store
+type segmentHeader struct {
+	Version    uint8
+	Codec      Codec
+	BaseOffset uint64
+	CreatedAt  time.Time
+}
+
+func newSegmentHeader(baseOffset uint64, c Config) segmentHeader {
+	return segmentHeader{
+		Version:    segmentVersion,
+		Codec:      c.Segment.Codec,
+		BaseOffset: baseOffset,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (h segmentHeader) encode() []byte {
+	b := make([]byte, segmentHeaderWidth)
+	copy(b, segmentMagic)
+	b[4] = h.Version
+	b[5] = byte(h.Codec)
+	enc.PutUint64(b[6:14], h.BaseOffset)
+	enc.PutUint64(b[14:22], uint64(h.CreatedAt.UnixNano()))
+	return b
+}
+
+// Reads the header at the start of the store
+// Returns errNoHeader if the store was written before stores had headers
+func readSegmentHeader(f *os.File) (segmentHeader, error) {
+	b := make([]byte, segmentHeaderWidth)
+	n, err := f.ReadAt(b, 0)
+	if err != nil && err != io.EOF {
+		return segmentHeader{}, err
+	}
+	// headerless stores start with a record's length, whose high bytes are always zero
+	if n < len(segmentMagic) || string(b[:len(segmentMagic)]) != segmentMagic {
+		return segmentHeader{}, errNoHeader
+	}
+	if n < segmentHeaderWidth {
+		return segmentHeader{}, errCorrupt
+	}
+	h := segmentHeader{
+		Version:    b[4],
+		Codec:      Codec(b[5]),
+		BaseOffset: enc.Uint64(b[6:14]),
+		CreatedAt:  time.Unix(0, int64(enc.Uint64(b[14:22]))),
+	}
+	if h.Version > segmentVersion {
+		return h, fmt.Errorf("log: unsupported segment version %d in %s", h.Version, f.Name())
+	}
+	return h, nil
+}
+
+// Rewrites the segment in the current format if it was written before stores had headers
+//
+// The new segment is built in a directory of its own and swapped in the same way as a compacted segment, so a crash
+// partway through leaves either the old segment or the new one
+func (l *Log) upgrade(baseOffset uint64) error {
+	storeFile, err := os.Open(filepath.Join(l.Dir, fmt.Sprintf("%d%s", baseOffset, ".store")))
+	if err != nil {
+		return err
+	}
+	_, err = readSegmentHeader(storeFile)
+	storeFile.Close()
+	if err != errNoHeader {
+		// already upgraded, or empty
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(storeFile.Name())
+	if err != nil {
+		return err
+	}
+	ib, err := ioutil.ReadFile(filepath.Join(l.Dir, fmt.Sprintf("%d%s", baseOffset, ".index")))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tmpDir := filepath.Join(l.Dir, upgradingDir)
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// the wider entries may not fit in an index of the configured size
+	c := l.Config
+	checksummed := !unchecksummed(b)
+	entries := uint64(len(ib)) / legacyEntWidth
+	if !checksummed {
+		// every record takes at least its length
+		entries = uint64(len(b)) / lenWidth
+	}
+	if size := entries * entWidth; size > c.Segment.MaxIndexBytes {
+		c.Segment.MaxIndexBytes = size
+	}
+	s, err := newSegment(tmpDir, baseOffset, c)
+	if err != nil {
+		return err
+	}
+	if checksummed {
+		err = s.upgradeChecksummed(b, ib)
+	} else {
+		err = s.upgradeUnchecksummed(b)
+	}
+	if err != nil {
+		s.Close()
+		return err
+	}
+	if err = s.Close(); err != nil {
+		return err
+	}
+
+	// keep the original modification time so retention still ages the segment from its last append
+	fi, err := os.Stat(storeFile.Name())
+	if err != nil {
+		return err
+	}
+	if err = os.Chtimes(s.store.Name(), fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpDir, filepath.Join(l.Dir, swappingDir)); err != nil {
+		return err
+	}
+	return l.finishSwap()
+}
+
+// Whether the headerless store frames its records without checksums, as stores did before records were checksummed
+// The first record's checksum tells them apart: an unchecksummed store has the start of the record where the checksum
+// would be
+func unchecksummed(b []byte) bool {
+	if uint64(len(b)) < headerWidth {
+		return uint64(len(b)) >= lenWidth
+	}
+	size := enc.Uint64(b[:lenWidth])
+	if size > uint64(len(b))-headerWidth {
+		return true
+	}
+	return checksum(b[headerWidth:headerWidth+size]) != enc.Uint32(b[lenWidth:headerWidth])
+}
+
+// Copies each indexed record's frame of a checksummed headerless store as is, so a damaged record is still reported
+// as damaged
+func (s *segment) upgradeChecksummed(b, ib []byte) error {
+	var end uint64
+	for entry := uint64(0); (entry+1)*legacyEntWidth <= uint64(len(ib)); entry++ {
+		e := ib[entry*legacyEntWidth:]
+		off, pos := uint64(enc.Uint32(e[:4])), enc.Uint64(e[4:legacyEntWidth])
+		// the rest is padding from an index that wasn't closed cleanly, or points past what made it to disk
+		if (entry > 0 && off == 0 && pos == 0) || pos+headerWidth > uint64(len(b)) {
+			break
+		}
+		if enc.Uint64(b[pos:pos+lenWidth]) > uint64(len(b))-pos-headerWidth {
+			break
+		}
+		width := headerWidth + enc.Uint64(b[pos:pos+lenWidth])
+		if err := s.appendFrame(off, b[pos:pos+width]); err != nil {
+			return err
+		}
+		end = pos + width
+	}
+	// anything after the last indexed record is left for recovery to sort out
+	if end < uint64(len(b)) {
+		if _, err := s.store.appendRaw(b[end:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Re-encodes the records of a store from before records were checksummed, which were never compacted so they take
+// consecutive offsets from the segment's base offset
+//
+// The index isn't needed, and may not have made it to disk for the last records appended, so the store is scanned
+// from the start
+func (s *segment) upgradeUnchecksummed(b []byte) error {
+	pos := uint64(0)
+	for rel := uint64(0); pos+lenWidth <= uint64(len(b)); rel++ {
+		size := enc.Uint64(b[pos : pos+lenWidth])
+		if size > uint64(len(b))-pos-lenWidth {
+			break
+		}
+		p := b[pos+lenWidth : pos+lenWidth+size]
+		pos += lenWidth + size
+		record := &api.Record{}
+		if err := proto.Unmarshal(p, record); err != nil {
+			// there's no checksum to tell a damaged record from a torn one, so keep it with a checksum it fails and
+			// let reads report it, or recovery cut it off if it's the last record
+			// the leading attributes byte says it has no timestamp, so the time index isn't thrown off
+			p = append([]byte{0}, p...)
+			frame := frameHeader(p)
+			enc.PutUint32(frame[lenWidth:], ^checksum(p))
+			if err = s.appendFrame(rel, append(frame, p...)); err != nil {
+				return err
+			}
+			continue
+		}
+		record.Offset = s.baseOffset + rel
+		p, err := encodeRecord(record, s.config.Segment.Codec, s.config.Encryption.Keyring)
+		if err != nil {
+			return err
+		}
+		if err = s.appendFrame(rel, append(frameHeader(p), p...)); err != nil {
+			return err
+		}
+	}
+	// a record cut off mid-write is cut off in the new frame too, since its length runs past the end of the store
+	// either way, so recovery drops it
+	if pos < uint64(len(b)) {
+		if _, err := s.store.appendRaw(b[pos:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
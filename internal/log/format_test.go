@@ -0,0 +1,181 @@
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	api "ledger/api/v1"
+)
+
+func TestUpgradeHeaderlessSegments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC)
+	// writes a segment the way it was written before stores had headers: frames from position 0 and 12-byte index
+	// entries with uint32 relative offsets
+	writeLegacy := func(baseOffset uint64, offsets []uint64, tail []byte, indexSize int) {
+		var store, index []byte
+		for _, off := range offsets {
+			p, err := encodeRecord(&api.Record{
+				Value:     []byte(fmt.Sprintf("record-%d", off)),
+				Offset:    off,
+				Timestamp: start.Add(time.Duration(off) * time.Minute).UnixNano(),
+			}, CodecNone, nil)
+			require.NoError(t, err)
+			entry := make([]byte, legacyEntWidth)
+			enc.PutUint32(entry[:4], uint32(off-baseOffset))
+			enc.PutUint64(entry[4:], uint64(len(store)))
+			index = append(index, entry...)
+			store = append(store, frameHeader(p)...)
+			store = append(store, p...)
+		}
+		store = append(store, tail...)
+		if len(index) < indexSize {
+			index = append(index, make([]byte, indexSize-len(index))...)
+		}
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.store", baseOffset)), store, 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.index", baseOffset)), index, 0644))
+	}
+	// a closed segment that was compacted, so offset 1 is missing
+	writeLegacy(0, []uint64{0, 2}, nil, 0)
+	// the active segment, left with a torn record and an untrimmed index by a crash
+	torn := []byte{0, 0, 0, 0, 0, 0, 0, 100, 1, 2}
+	writeLegacy(3, []uint64{3, 4}, torn, 1024)
+
+	c := Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(torn)), l.Recovery().DiscardedBytes)
+
+	for _, s := range l.segments {
+		require.Equal(t, uint8(segmentVersion), s.header.Version)
+		require.Equal(t, s.baseOffset, s.header.BaseOffset)
+	}
+	check := func(l *Log) {
+		for offset, want := range map[uint64]uint64{0: 0, 1: 2, 2: 2, 3: 3, 4: 4} {
+			record, err := l.Read(offset)
+			require.NoError(t, err)
+			require.Equal(t, want, record.Offset)
+			require.Equal(t, fmt.Sprintf("record-%d", want), string(record.Value))
+		}
+		off, err := l.OffsetForTime(start.Add(3 * time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), off)
+	}
+	check(l)
+
+	off, err := l.Append(&api.Record{Value: []byte("record-5")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), off)
+	require.NoError(t, l.Close())
+
+	// nothing's left over from the upgrade, and reopening doesn't upgrade again
+	for _, name := range []string{upgradingDir, swappingDir} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		require.True(t, os.IsNotExist(err))
+	}
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	check(l)
+	require.NoError(t, l.Close())
+}
+
+func TestUpgradeUnchecksummedSegments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// writes a segment the way the first version of the log did: each record marshaled as is behind its 8-byte
+	// length, with no checksum, attributes or timestamp, and 12-byte index entries for the first indexed records
+	writeBaseline := func(baseOffset uint64, offsets []uint64, indexed int, tail []byte) {
+		var store, index []byte
+		for i, off := range offsets {
+			p, err := proto.Marshal(&api.Record{Value: []byte(fmt.Sprintf("record-%d", off)), Offset: off})
+			require.NoError(t, err)
+			if i < indexed {
+				entry := make([]byte, legacyEntWidth)
+				enc.PutUint32(entry[:4], uint32(off-baseOffset))
+				enc.PutUint64(entry[4:], uint64(len(store)))
+				index = append(index, entry...)
+			}
+			size := make([]byte, lenWidth)
+			enc.PutUint64(size, uint64(len(p)))
+			store = append(append(store, size...), p...)
+		}
+		store = append(store, tail...)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.store", baseOffset)), store, 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.index", baseOffset)), index, 0644))
+	}
+	writeBaseline(0, []uint64{0, 1, 2}, 3, nil)
+	// the active segment, where a crash cut off the last record and the index never got the one before it
+	torn := []byte{0, 0, 0, 0, 0, 0, 0, 100, 1, 2}
+	writeBaseline(3, []uint64{3, 4}, 1, torn)
+
+	c := Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	c.Segment.Codec = CodecSnappy
+	l, err := NewLog(dir, c)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(torn)), l.Recovery().DiscardedBytes)
+	check := func(l *Log) {
+		for offset := uint64(0); offset < 5; offset++ {
+			record, err := l.Read(offset)
+			require.NoError(t, err)
+			require.Equal(t, offset, record.Offset)
+			require.Equal(t, fmt.Sprintf("record-%d", offset), string(record.Value))
+		}
+	}
+	check(l)
+	off, err := l.Append(&api.Record{Value: []byte("record-5")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), off)
+	require.NoError(t, l.Close())
+
+	l, err = NewLog(dir, c)
+	require.NoError(t, err)
+	check(l)
+	require.NoError(t, l.Close())
+}
+
+func TestSegmentHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "header-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	c.Segment.Codec = CodecSnappy
+	s, err := newSegment(dir, 16, c)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	s, err = newSegment(dir, 16, c)
+	require.NoError(t, err)
+	require.Equal(t, uint8(segmentVersion), s.header.Version)
+	require.Equal(t, CodecSnappy, s.header.Codec)
+	require.Equal(t, uint64(16), s.header.BaseOffset)
+	require.WithinDuration(t, time.Now(), s.header.CreatedAt, time.Minute)
+	require.NoError(t, s.Close())
+
+	// a segment from a newer format is refused rather than misread
+	f, err := os.OpenFile(filepath.Join(dir, "16.store"), os.O_RDWR, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{segmentVersion + 1}, 4)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = newSegment(dir, 16, c)
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+
+	api "ledger/api/v1"
+)
+
+var errNoGroup = errors.New("log: consumer groups need a name")
+
+// Records the next offset the consumer group consumes from the topic
+func (t *Topics) CommitOffset(topic, group string, offset uint64) error {
+	if group == "" {
+		return errNoGroup
+	}
+	if _, err := t.Log(topic); err != nil {
+		return err
+	}
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	t.offsetsMu.Lock()
+	defer t.offsetsMu.Unlock()
+	offsets, ok := t.offsets[topic]
+	if !ok {
+		offsets = make(map[string]uint64)
+		t.offsets[topic] = offsets
+	}
+	offsets[group] = offset
+	return nil
+}
+
+// Returns the offset the consumer group last committed for the topic, or api.ErrOffsetNotCommitted
+func (t *Topics) CommittedOffset(topic, group string) (uint64, error) {
+	if _, err := t.Log(topic); err != nil {
+		return 0, err
+	}
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	t.offsetsMu.Lock()
+	defer t.offsetsMu.Unlock()
+	offset, ok := t.offsets[topic][group]
+	if !ok {
+		return 0, api.ErrOffsetNotCommitted{Group: group, Topic: topic}
+	}
+	return offset, nil
+}
+
+// Returns the offset the topic's next record gets
+func (t *Topics) EndOffset(topic string) (uint64, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return 0, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.activeSegment.nextOffset, nil
+}
+
+// Returns the topic's committed offsets encoded for a snapshot, or nil if no group has committed one
+func (t *Topics) snapshotOffsets(topic string) ([]byte, error) {
+	t.offsetsMu.Lock()
+	defer t.offsetsMu.Unlock()
+	offsets := t.offsets[topic]
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(offsets)
+}
+
+// Replaces the topic's committed offsets with the ones a snapshot holds
+func (t *Topics) restoreOffsets(topic string, b []byte) error {
+	offsets := make(map[string]uint64)
+	if err := json.Unmarshal(b, &offsets); err != nil {
+		return err
+	}
+	t.offsetsMu.Lock()
+	defer t.offsetsMu.Unlock()
+	t.offsets[topic] = offsets
+	return nil
+}
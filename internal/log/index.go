@@ -3,13 +3,14 @@ package log
 import (
 	"io"
 	"os"
+	"sort"
 
 	"github.com/tysontate/gommap"
 )
 
 var (
-	// offsets are stored as uint32
-	offWidth uint64 = 4
+	// offsets are stored as uint64, so a segment isn't limited to 2^32 records
+	offWidth uint64 = 8
 	// positions are stored as uint64
 	posWidth uint64 = 8
 	// entWidth is used to jump to the position of an entry
@@ -23,23 +24,45 @@ type index struct {
 	mmap gommap.MMap
 	// the size of the index and where to write the next entry appended to the index
 	size uint64
+	// a read-only index's file is mapped privately, so entries written to it never reach the file
+	readOnly bool
 }
 
 // creates an index from the given file
-func newIndex(f *os.File, c Config) (*index, error) {
+func newIndex(f *os.File, c Config, readOnly bool) (*index, error) {
 	idx := &index{
-		file: f,
+		file:     f,
+		readOnly: readOnly,
 	}
 	fi, err := os.Stat(f.Name())
 	if err != nil {
 		return nil, err
 	}
 	idx.size = uint64(fi.Size())
+	if readOnly {
+		// the file's left the size it is, so there's only room for the entries it already has
+		if idx.size > 0 {
+			if idx.mmap, err = gommap.Map(
+				idx.file.Fd(),
+				gommap.PROT_READ|gommap.PROT_WRITE,
+				gommap.MAP_PRIVATE,
+			); err != nil {
+				return nil, err
+			}
+		}
+		idx.size = idx.entries()
+		return idx, nil
+	}
 	// we grow the file here because we can't resize it once it is memory-mapped
 	// there may be space between the last index entry and the end of the file
 	// when we close the index, we must remove this empty space
+	// an index written with a larger MaxIndexBytes keeps its size so none of its entries are lost
+	size := c.Segment.MaxIndexBytes
+	if idx.size > size {
+		size = idx.size
+	}
 	if err = os.Truncate(
-		f.Name(), int64(c.Segment.MaxIndexBytes),
+		f.Name(), int64(size),
 	); err != nil {
 		return nil, err
 	}
@@ -50,12 +73,46 @@ func newIndex(f *os.File, c Config) (*index, error) {
 	); err != nil {
 		return nil, err
 	}
+	idx.size = idx.entries()
 	return idx, nil
 }
 
+// Returns the size of the entries in the first size bytes of the file
+// An index that wasn't closed cleanly is still padded out with zeros. Records come after the segment's header so no
+// entry has a zero position, and the entries end at the first one that does.
+func (i *index) entries() uint64 {
+	n := int(i.size / entWidth)
+	return uint64(sort.Search(n, func(j int) bool {
+		pos := uint64(j)*entWidth + offWidth
+		return enc.Uint64(i.mmap[pos:pos+posWidth]) == 0
+	})) * entWidth
+}
+
+// Copies a read-only index's entries into memory with room for the given number of bytes of entries, so it can be
+// rebuilt without being limited to the size its file was left at
+func (i *index) unshare(size uint64) {
+	if uint64(len(i.mmap)) > size {
+		size = uint64(len(i.mmap))
+	}
+	b := make(gommap.MMap, size)
+	copy(b, i.mmap)
+	if i.mmap != nil {
+		i.mmap.UnsafeUnmap()
+	}
+	i.mmap = b
+}
+
+// flushes the memory-mapped entries to the persisted file
+func (i *index) Sync() error {
+	return i.mmap.Sync(gommap.MS_SYNC)
+}
+
 // ensures the memory-mapped file syncs its data to the persisted file and flushes its contents to the file before
 // closing
 func (i *index) Close() error {
+	if i.readOnly {
+		return i.file.Close()
+	}
 	if err := i.mmap.Sync(gommap.MS_SYNC); err != nil {
 		return err
 	}
@@ -70,15 +127,13 @@ func (i *index) Close() error {
 }
 
 // Read accepts an offset and returns the record's offset and position in the store
-//
 // The offset is relative to the segment's base offset
-// We use relative offsets to reduce the size of the indexes by storing offsets as uint32
 //
 // Return values:
 // - record's offset
 // - record's position
 // - error
-func (i *index) Read(in int64) (uint32, uint64, error) {
+func (i *index) Read(in int64) (uint64, uint64, error) {
 	if i.size == 0 {
 		return 0, 0, io.EOF
 	}
@@ -98,13 +153,25 @@ func (i *index) Read(in int64) (uint32, uint64, error) {
 		return 0, 0, io.EOF
 	}
 
-	out := enc.Uint32(i.mmap[indexPos : indexPos+offWidth])
+	out := enc.Uint64(i.mmap[indexPos : indexPos+offWidth])
 	pos := enc.Uint64(i.mmap[indexPos+offWidth : indexPos+entWidth])
 	return out, pos, nil
 }
 
+// Search returns the number of the first entry whose offset is at or after the given relative offset
+//
+// Entries are always sorted by offset, but compaction can leave gaps between them, so the entry for an offset isn't
+// necessarily at the entry number matching it
+func (i *index) Search(off uint64) int64 {
+	n := int(i.size / entWidth)
+	return int64(sort.Search(n, func(j int) bool {
+		entryPos := uint64(j) * entWidth
+		return enc.Uint64(i.mmap[entryPos:entryPos+offWidth]) >= off
+	}))
+}
+
 // Appends the given offset and position to the index
-func (i *index) Write(off uint32, pos uint64) error {
+func (i *index) Write(off uint64, pos uint64) error {
 	// check if we have reached the file's size limit
 	// does another index entry exceed the length of the memory-mapped file?
 	if uint64(len(i.mmap)) < i.size+entWidth {
@@ -112,7 +179,7 @@ func (i *index) Write(off uint32, pos uint64) error {
 	}
 
 	// appends the record's offset
-	enc.PutUint32(i.mmap[i.size:i.size+offWidth], off)
+	enc.PutUint64(i.mmap[i.size:i.size+offWidth], off)
 	// appends the record's position
 	enc.PutUint64(i.mmap[i.size+offWidth:i.size+entWidth], pos)
 
@@ -121,6 +188,14 @@ func (i *index) Write(off uint32, pos uint64) error {
 	return nil
 }
 
+// Truncate drops the entries after the given size
+func (i *index) Truncate(size uint64) {
+	for j := size; j < i.size; j++ {
+		i.mmap[j] = 0
+	}
+	i.size = size
+}
+
 func (i *index) Name() string {
 	return i.file.Name()
 }
@@ -16,17 +16,17 @@ func TestIndex(t *testing.T) {
 
 	c := Config{}
 	c.Segment.MaxIndexBytes = 1024
-	idx, err := newIndex(f, c)
+	idx, err := newIndex(f, c, false)
 	require.NoError(t, err)
 	_, _, err = idx.Read(-1)
 	require.Error(t, err)
 
 	entries := []struct {
-		Off uint32
+		Off uint64
 		Pos uint64
 	}{
-		{Off: 0, Pos: 0},
-		{Off: 1, Pos: 10},
+		{Off: 0, Pos: 22},
+		{Off: 1, Pos: 32},
 	}
 
 	for _, want := range entries {
@@ -45,11 +45,11 @@ func TestIndex(t *testing.T) {
 
 	// index should build its state from the existing file
 	f, _ = os.OpenFile(f.Name(), os.O_RDWR, 0600)
-	idx, err = newIndex(f, c)
+	idx, err = newIndex(f, c, false)
 	require.NoError(t, err)
 	off, pos, err := idx.Read(-1)
 	require.NoError(t, err)
-	require.Equal(t, uint32(1), off)
+	require.Equal(t, uint64(1), off)
 	require.Equal(t, entries[1].Pos, pos)
 }
 
@@ -0,0 +1,177 @@
+package log
+
+import (
+	"errors"
+	"io"
+
+	api "ledger/api/v1"
+)
+
+// returned once the log an iterator reads is closed, e.g. because its topic was deleted
+var errLogClosed = errors.New("log: closed")
+
+// Iterator walks the log's records in offset order
+//
+// It keeps its place in the segment it's reading, so moving to the next record doesn't search the log again. It's
+// safe to use while records are appended: once it has caught up with the log, Next returns false, and calling Next
+// again later picks up any records appended since. The iterator itself is meant for a single goroutine
+//
+//	it := log.Iterator(from, to, 0)
+//	for it.Next() {
+//		record := it.Record()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+//
+// To tail the log, wait on Wait and call Next again once it's closed
+type Iterator struct {
+	log *Log
+	// offset of the next record to read
+	next uint64
+	// offset to stop at, exclusive
+	to uint64
+	// bytes the iterator may read, 0 means no limit
+	maxBytes uint64
+	read     uint64
+
+	// the segment being read and where the next record is in it
+	// the segment is looked up again if retention or compaction removes or replaces it
+	segment *segment
+	cursor  cursor
+	// the frame holding the record Next last moved to, which the cursor moves past on the next call
+	frame frameInfo
+
+	record *api.Record
+	err    error
+	// set once the iterator has reached the end of its range or byte budget
+	done bool
+}
+
+// Returns an iterator over the records from offset from up to, but not including, offset to
+//
+// Iteration starts at the lowest offset if from has already been deleted, and skips the offsets compaction removed
+// maxBytes bounds how many bytes of records are read, counted by each record's encoded size, but the first record is
+// always returned however big it is. Use 0 for no limit
+func (l *Log) Iterator(from, to, maxBytes uint64) *Iterator {
+	return &Iterator{
+		log:      l,
+		next:     from,
+		to:       to,
+		maxBytes: maxBytes,
+	}
+}
+
+// Moves to the next record, returning false once there are no more records to read or the iterator failed
+func (it *Iterator) Next() bool {
+	it.record = nil
+	if it.done || it.err != nil || it.next >= it.to {
+		return false
+	}
+	// records older than every local segment may be in the archive
+	p, off, ok, err := it.log.readArchived(it.next)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if !ok {
+		if p, off, ok = it.advance(); !ok {
+			return false
+		}
+	}
+	if off >= it.to {
+		it.done = true
+		return false
+	}
+	width := headerWidth + uint64(len(p))
+	if it.maxBytes > 0 && it.read > 0 && it.read+width > it.maxBytes {
+		it.done = true
+		return false
+	}
+	record, err := decodeRecordAt(p, off, it.log.Config.Encryption.Keyring)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.read += width
+	it.next = off + 1
+	it.record = record
+	return true
+}
+
+// Reads the next record's payload and offset, moving on to the next segment when the current one runs out
+func (it *Iterator) advance() ([]byte, uint64, bool) {
+	l := it.log
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		it.err = errLogClosed
+		return nil, 0, false
+	}
+	i := l.segmentIndex(it.segment)
+	if i == -1 {
+		i = it.seek()
+	}
+	for i < len(l.segments) {
+		s := l.segments[i]
+		if s != it.segment {
+			it.segment = s
+			it.frame = frameInfo{}
+			var rel uint64
+			if it.next > s.baseOffset {
+				rel = it.next - s.baseOffset
+			}
+			var err error
+			if it.cursor, err = s.seek(rel); err != nil && err != io.EOF {
+				it.err = err
+				return nil, 0, false
+			}
+		} else if it.frame.n > 0 {
+			it.cursor = s.advance(it.cursor, it.frame)
+			it.frame = frameInfo{}
+		}
+		if it.cursor.pos >= s.store.size {
+			// we've read every record in the segment
+			i++
+			continue
+		}
+		p, f, err := s.readAt(it.cursor)
+		if err != nil {
+			it.err = err
+			return nil, 0, false
+		}
+		it.frame = f
+		if offset := s.baseOffset + it.cursor.off; offset >= it.next {
+			return p, offset, true
+		}
+		// we got to the segment's end before the offset we're after was appended
+	}
+	// caught up with the active segment, the next call carries on from the same record
+	return nil, 0, false
+}
+
+// Returns the position of the segment holding the next offset, or of the first segment if it's been deleted
+// Must be called with the log's lock held
+func (it *Iterator) seek() int {
+	it.segment = nil
+	if i := it.log.segmentFor(it.next); i != -1 {
+		return i
+	}
+	return 0
+}
+
+// Returns a channel that's closed once there may be more records to read, for tailing the log after Next runs out
+// Next may still return false once it's closed, e.g. if the records appended are all before the iterator's offset
+func (it *Iterator) Wait() <-chan struct{} {
+	return it.log.Wait(it.next)
+}
+
+// Returns the record Next moved to
+func (it *Iterator) Record() *api.Record {
+	return it.record
+}
+
+// Returns the error that stopped the iterator, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -1,6 +1,8 @@
 package log
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -9,6 +11,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
 
 	api "ledger/api/v1"
 )
@@ -16,6 +21,8 @@ import (
 // Ordered, append-only, write-ahead log
 type Log struct {
 	mu sync.RWMutex
+	// only one compaction or upload to the archive runs at a time
+	compactMu sync.Mutex
 
 	Dir    string
 	Config Config
@@ -24,88 +31,485 @@ type Log struct {
 	activeSegment *segment
 	// list of segments
 	segments []*segment
+	// what the startup recovery pass discarded from the active segment
+	recovery Recovery
+	// with the hash chain enabled, the last record's hash and whether that record is a checkpoint
+	head           []byte
+	headCheckpoint bool
+	// segments uploaded to the archive
+	manifest manifest
+	// segments fetched back from the archive, least recently read first, and the directory they're downloaded into
+	cacheMu  sync.Mutex
+	cache    []*segment
+	cacheDir string
+	// opened by OpenReadOnly, so nothing in Dir is changed
+	readOnly bool
+
+	// with SyncBatch, the appends waiting on the next fsync and how many records they hold
+	group   *syncGroup
+	pending uint64
+	// closed to stop the log's background work: group commits, retention and compaction
+	stop chan struct{}
+	// closed and replaced after every append, waking whoever's waiting for the log to grow
+	appended chan struct{}
+	closed   bool
+	// tracks the background goroutines so Close can wait for them
+	background sync.WaitGroup
+}
+
+// Describes what NewLog cut from the end of the active segment to get back to the last fully written record
+type Recovery struct {
+	// base offset of the recovered segment
+	BaseOffset uint64
+	// offset the next appended record will get
+	NextOffset uint64
+	// bytes at the end of the store that didn't hold a complete, valid record
+	DiscardedBytes uint64
+	// index entries that pointed at missing or invalid records
+	DiscardedEntries uint64
 }
 
+// returned by anything that would change a log opened by OpenReadOnly
+var errReadOnly = errors.New("log: opened read-only")
+
 func NewLog(dir string, c Config) (*Log, error) {
+	l := &Log{
+		Dir:    dir,
+		Config: withDefaults(c),
+	}
+	if err := l.setup(c.Segment.InitialOffset); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Opens an existing log to read, for inspecting the log of a node that isn't running, without changing anything in
+// its directory
+//
+// Nothing on disk is recovered, upgraded or swapped in and no background work is started. A record cut off at the end
+// of the active segment is left where it is, reads stop before it and Recovery reports it. A log that still needs
+// upgrading, or that was stopped partway through swapping in a compacted segment, is refused until a node has opened
+// it. Segments read from the archive are downloaded into a temporary directory that Close deletes.
+func OpenReadOnly(dir string, c Config) (*Log, error) {
+	l := &Log{
+		Dir:      dir,
+		Config:   withDefaults(c),
+		readOnly: true,
+	}
+	if err := l.open(); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Fills in the defaults for anything the config leaves unset
+func withDefaults(c Config) Config {
+	if c.Logger == nil {
+		c.Logger = hclog.New(&hclog.LoggerOptions{Name: "ledger"})
+	}
 	if c.Segment.MaxStoreBytes == 0 {
-		c.Segment.MaxStoreBytes = 1024
+		c.Segment.MaxStoreBytes = 1 << 30
 	}
 	if c.Segment.MaxIndexBytes == 0 {
-		c.Segment.MaxIndexBytes = 1024
+		c.Segment.MaxIndexBytes = 10 << 20
 	}
-	l := &Log{
-		Dir:    dir,
-		Config: c,
+	if c.Segment.TimeIndexIntervalBytes == 0 {
+		c.Segment.TimeIndexIntervalBytes = 4096
+	}
+	if c.Segment.Sync == SyncBatch && c.Segment.SyncInterval == 0 {
+		c.Segment.SyncInterval = 10 * time.Millisecond
+	}
+	if c.Retention.CheckInterval == 0 {
+		c.Retention.CheckInterval = time.Minute
+	}
+	if c.Tiering.CacheBytes == 0 {
+		c.Tiering.CacheBytes = 1 << 30
+	}
+	if c.Tiering.Interval == 0 {
+		c.Tiering.Interval = time.Minute
+	}
+	if c.Compaction.Interval == 0 {
+		c.Compaction.Interval = 10 * time.Minute
+	}
+	return c
+}
+
+// Opens the log's existing segments, or creates the first one at the initial offset, and starts group committing if
+// the sync policy asks for it
+func (l *Log) setup(initialOffset uint64) error {
+	c := l.Config
+	dir := l.Dir
+	l.appended = make(chan struct{})
+	l.closed = false
+	l.cacheDir = path.Join(dir, archiveCacheDir)
+
+	// finish swapping in a compacted segment if we crashed partway through, or drop one that was never completed
+	if err := l.finishSwap(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(path.Join(dir, compactingDir)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(path.Join(dir, upgradingDir)); err != nil {
+		return err
+	}
+	// segments fetched from the archive are fetched again when they're next read
+	if err := os.RemoveAll(l.cacheDir); err != nil {
+		return err
+	}
+	if err := l.loadManifest(); err != nil {
+		return err
 	}
 
 	// load existing log files if they exist
-	files, err := ioutil.ReadDir(dir)
+	baseOffsets, err := l.baseOffsets()
+	if err != nil {
+		return err
+	}
+	for _, baseOffset := range baseOffsets {
+		// segments written in an older format are rewritten in the current one before we open them
+		if err = l.upgrade(baseOffset); err != nil {
+			return err
+		}
+		if err = l.newSegment(baseOffset); err != nil {
+			return err
+		}
+	}
+	if l.segments == nil {
+		off := initialOffset
+		if remote := l.remote(); len(remote) > 0 {
+			off = remote[len(remote)-1].NextOffset
+		}
+		if err = l.newSegment(off); err != nil {
+			return err
+		}
+	}
+	// older segments were full when we rolled past them, so the next segment's base offset tells us where each one
+	// ends, even if we didn't shut down cleanly
+	for i := 0; i < len(l.segments)-1; i++ {
+		l.segments[i].nextOffset = l.segments[i+1].baseOffset
+	}
+	// the active segment is the only one that could have been cut off mid-append
+	if l.recovery, err = l.activeSegment.recover(); err != nil {
+		return err
+	}
+	// we may have stopped after filling the active segment but before rolling it
+	if l.activeSegment.IsMaxed() {
+		if err = l.newSegment(l.activeSegment.nextOffset); err != nil {
+			return err
+		}
+	}
+	if err = l.loadHead(); err != nil {
+		return err
+	}
+
+	l.stop = make(chan struct{})
+	if c.Segment.Sync == SyncBatch {
+		l.group = newSyncGroup()
+		l.background.Add(1)
+		go l.syncLoop(c.Segment.SyncInterval)
+	}
+	if c.Retention.MaxBytes > 0 || c.Retention.MaxAge > 0 {
+		l.background.Add(1)
+		go l.retentionLoop(c.Retention.CheckInterval)
+	}
+	if c.Compaction.Enabled {
+		l.background.Add(1)
+		go l.compactionLoop(c.Compaction.Interval)
+	}
+	if c.Tiering.Archive != nil {
+		l.background.Add(1)
+		go l.tieringLoop(c.Tiering.Interval)
+	}
+	return nil
+}
+
+// Opens the existing segments for OpenReadOnly, recovering the active one in memory only
+func (l *Log) open() error {
+	l.appended = make(chan struct{})
+	if _, err := os.Stat(path.Join(l.Dir, swappingDir)); err == nil {
+		return fmt.Errorf("log: %s was stopped partway through swapping in a compacted segment", l.Dir)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	var err error
+	if l.cacheDir, err = ioutil.TempDir("", "ledger-archive-cache"); err != nil {
+		return err
+	}
+	if err = l.readManifest(); err != nil {
+		return err
+	}
+	baseOffsets, err := l.baseOffsets()
+	if err != nil {
+		return err
+	}
+	for _, baseOffset := range baseOffsets {
+		// local copies of archived segments that eviction didn't finish deleting
+		if i := l.archivedIndex(baseOffset); i != -1 && !l.manifest.Segments[i].Local {
+			continue
+		}
+		s, err := openSegment(l.Dir, baseOffset, l.Config, true)
+		if err == errNoHeader {
+			return fmt.Errorf("log: segment %d is in a format that's upgraded when a node opens the log", baseOffset)
+		}
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, s)
+		l.activeSegment = s
+	}
+	if l.segments == nil {
+		return fmt.Errorf("log: %s has no segments", l.Dir)
+	}
+	for i := 0; i < len(l.segments)-1; i++ {
+		l.segments[i].nextOffset = l.segments[i+1].baseOffset
+	}
+	if l.recovery, err = l.activeSegment.recover(); err != nil {
+		return err
+	}
+	return l.loadHead()
+}
+
+// Returns the base offsets of the segments in the log's directory, in order
+func (l *Log) baseOffsets() ([]uint64, error) {
+	files, err := ioutil.ReadDir(l.Dir)
 	if err != nil {
 		return nil, err
 	}
 	var baseOffsets []uint64
 	for _, file := range files {
-		// get the
+		// every segment has a store named after its base offset, e.g. 16.store
+		// anything else in the directory isn't a segment
+		if file.IsDir() || path.Ext(file.Name()) != ".store" {
+			continue
+		}
 		offStr := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
-		off, _ := strconv.ParseUint(offStr, 10, 0)
+		off, err := strconv.ParseUint(offStr, 10, 0)
+		if err != nil {
+			continue
+		}
 		baseOffsets = append(baseOffsets, off)
 	}
 	sort.Slice(baseOffsets, func(i, j int) bool {
 		return baseOffsets[i] < baseOffsets[j]
 	})
-	for i := 0; i < len(baseOffsets); i++ {
-		if err = l.newSegment(baseOffsets[i]); err != nil {
-			return nil, err
-		}
-		// baseOffset contains dup for index and store so we skip the dup
-		i++
-	}
-	if l.segments == nil {
-		if err = l.newSegment(c.Segment.InitialOffset); err != nil {
-			return nil, err
-		}
-	}
-	return l, nil
+	return baseOffsets, nil
+}
+
+// Reports what the startup recovery pass discarded, or for a log opened read-only, what it would discard
+func (l *Log) Recovery() Recovery {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.recovery
 }
 
 // append the record to the log and return its offset
+// Returns once the record is as durable as the log's sync policy asks for
 func (l *Log) Append(record *api.Record) (uint64, error) {
+	off, wait, err := l.append(record)
+	if err != nil {
+		return 0, err
+	}
+	return off, wait()
+}
+
+// Appends the record and returns its offset along with a function that blocks until the record is durable
+func (l *Log) append(record *api.Record) (uint64, func() error, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	off, err := l.write(record)
+	if err != nil {
+		return 0, nil, err
+	}
+	l.notify()
+	return off, l.commit(1), nil
+}
+
+// Appends the records as a unit: either every record is appended, at consecutive offsets, or none of them are
+// Returns the records' offsets once they're as durable as the log's sync policy asks for
+func (l *Log) AppendBatch(records []*api.Record) ([]uint64, error) {
+	offsets, wait, err := l.appendBatch(records)
+	if err != nil {
+		return nil, err
+	}
+	return offsets, wait()
+}
+
+// Appends the records and returns their offsets along with a function that blocks until they're durable
+// The records go into the active segment together, as one frame compressed with the segment's codec
+func (l *Log) appendBatch(records []*api.Record) ([]uint64, func() error, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if l.readOnly {
+		return nil, nil, errReadOnly
+	}
+	// rolling over to a new segment after the batch may fail, so remember where it started
+	active, n := l.activeSegment, len(l.segments)
+	mark := active.mark()
+	head, headCheckpoint := l.head, l.headCheckpoint
+	offsets := make([]uint64, len(records))
+	now := time.Now().UnixNano()
+	for i, record := range records {
+		offsets[i] = active.nextOffset + uint64(i)
+		record.Offset = offsets[i]
+		if record.Timestamp == 0 {
+			record.Timestamp = now
+		}
+		// each record in the batch links to the one before it
+		l.link(record)
+		l.linked(record)
+	}
+	err := active.appendBatch(records)
+	// if the segment is at its max size, allocate a new segment
+	if err == nil && active.IsMaxed() {
+		if err = l.seal(); err == nil {
+			err = l.newSegment(active.nextOffset)
+		}
+	}
+	if err != nil {
+		if rollbackErr := l.rollback(active, n, mark); rollbackErr != nil {
+			return nil, nil, rollbackErr
+		}
+		l.head, l.headCheckpoint = head, headCheckpoint
+		return nil, nil, err
+	}
+	l.notify()
+	return offsets, l.commit(uint64(len(records))), nil
+}
+
+// Cuts the log back to the mark taken on the segment that was active, removing any segments created since
+// Must be called with the log's lock held
+func (l *Log) rollback(active *segment, n int, mark segmentMark) error {
+	for _, s := range l.segments[n:] {
+		if err := s.Remove(); err != nil {
+			return err
+		}
+	}
+	l.segments = l.segments[:n]
+	l.activeSegment = active
+	return active.rollback(mark)
+}
+
+// Writes the record to the active segment without making it durable
+// Must be called with the log's lock held
+func (l *Log) write(record *api.Record) (uint64, error) {
+	if l.readOnly {
+		return 0, errReadOnly
+	}
+	l.link(record)
 	off, err := l.activeSegment.Append(record)
 	if err != nil {
 		return 0, err
 	}
+	l.linked(record)
 	// if the segment is at its max size, allocate a new segment
 	if l.activeSegment.IsMaxed() {
+		if err = l.seal(); err != nil {
+			return 0, err
+		}
 		err = l.newSegment(off + 1)
 	}
 	return off, err
 }
 
+// Wakes whoever's waiting for the log to grow
+// Must be called with the log's lock held
+func (l *Log) notify() {
+	close(l.appended)
+	l.appended = make(chan struct{})
+}
+
+// Returns a channel that's closed once the log holds the offset, or once the log's closed
+// It's also closed by appends that stop short of the offset, so check the log again each time it's closed
+func (l *Log) Wait(offset uint64) <-chan struct{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed || l.activeSegment.nextOffset > offset {
+		return closedChan
+	}
+	return l.appended
+}
+
+// returned by Wait when there's nothing to wait for
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
 // get a record by offset
 func (l *Log) Read(offset uint64) (*api.Record, error) {
 	l.mu.RLock()
+	i := l.segmentFor(offset)
+	if i == -1 {
+		// the offset comes before every local segment, so it may be in one that's only in the archive
+		remote := l.remote()
+		l.mu.RUnlock()
+		if len(remote) == 0 || offset < remote[0].BaseOffset {
+			return nil, api.ErrOffsetOutOfRange{Offset: offset}
+		}
+		return l.readArchivedRecord(offset)
+	}
 	defer l.mu.RUnlock()
 
-	var s *segment
-	// segments are ordered oldest to newest
-	for _, segment := range l.segments {
-		if segment.baseOffset <= offset {
-			s = segment
-			break
-		}
+	if l.segments[i].nextOffset <= offset {
+		return nil, api.ErrOffsetOutOfRange{Offset: offset}
 	}
+	return l.segments[i].Read(offset)
+}
 
-	if s == nil || s.nextOffset <= offset {
+func (l *Log) readArchivedRecord(offset uint64) (*api.Record, error) {
+	p, off, ok, err := l.readArchived(offset)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// the segment was deleted or fetched back
 		return nil, api.ErrOffsetOutOfRange{Offset: offset}
 	}
-	return s.Read(offset)
+	return decodeRecordAt(p, off, l.Config.Encryption.Keyring)
+}
+
+// Returns the position of the segment that holds the offset, the last one whose base offset is at or before it, or -1
+// if the offset comes before every segment
+// Must be called with the log's lock held
+func (l *Log) segmentFor(offset uint64) int {
+	// segments are ordered oldest to newest, so find the first one that starts after the offset
+	return sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].baseOffset > offset
+	}) - 1
+}
+
+// Returns the offset of the first record with a timestamp at or after t
+// If every record is older, returns the offset the next appended record will get, so consuming from it only picks up
+// new records
+func (l *Log) OffsetForTime(t time.Time) (uint64, error) {
+	timestamp := t.UnixNano()
+	// segments that are only in the archive are older than every local one
+	if off, ok, err := l.archivedOffsetForTime(timestamp); err != nil || ok {
+		return off, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, s := range l.segments {
+		off, ok, err := s.OffsetForTime(timestamp)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return off, nil
+		}
+	}
+	return l.activeSegment.nextOffset, nil
 }
 
 func (l *Log) LowestOffset() (uint64, error) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
+	if remote := l.remote(); len(remote) > 0 {
+		return remote[0].BaseOffset, nil
+	}
 	return l.segments[0].baseOffset, nil
 }
 
@@ -122,9 +526,21 @@ func (l *Log) HighestOffset() (uint64, error) {
 func (l *Log) Truncate(lowest uint64) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if l.readOnly {
+		return errReadOnly
+	}
+	for remote := l.remote(); len(remote) > 0 && remote[0].NextOffset <= lowest+1; remote = l.remote() {
+		if err := l.deleteArchived(remote[0].BaseOffset); err != nil {
+			return err
+		}
+	}
 	var segments []*segment
 	for _, s := range l.segments {
 		if s.nextOffset <= lowest+1 {
+			// dropping it from the manifest first means a crash in between leaves a segment that's uploaded again
+			if err := l.deleteArchived(s.baseOffset); err != nil {
+				return err
+			}
 			if err := s.Remove(); err != nil {
 				return err
 			}
@@ -147,44 +563,157 @@ func (l *Log) newSegment(off uint64) error {
 }
 
 func (l *Log) Close() error {
+	if l.stop != nil {
+		close(l.stop)
+		l.background.Wait()
+		l.stop = nil
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	// release anyone still waiting on a group commit or for the log to grow
+	if l.group != nil {
+		l.syncGroup()
+	}
+	if !l.closed {
+		l.closed = true
+		l.notify()
+	}
 	for _, segment := range l.segments {
 		if err := segment.Close(); err != nil {
 			return err
 		}
 	}
+	l.cacheMu.Lock()
+	defer l.cacheMu.Unlock()
+	for _, segment := range l.cache {
+		if err := segment.Close(); err != nil {
+			return err
+		}
+	}
+	l.cache = nil
+	if l.readOnly && l.cacheDir != "" {
+		return os.RemoveAll(l.cacheDir)
+	}
 	return nil
 }
 
+// Closes the log and deletes its segments, including the ones uploaded to the archive
+// Only deleting the log for good, like deleting its topic, should call this, anything else that clears the log, like
+// restoring a snapshot, uses Reset so the archive is left alone
 func (l *Log) Remove() error {
+	if l.readOnly {
+		return errReadOnly
+	}
 	if err := l.Close(); err != nil {
 		return err
 	}
+	for _, a := range l.manifest.Segments {
+		if l.Config.Tiering.Archive == nil {
+			break
+		}
+		for _, ext := range segmentExts {
+			if err := l.Config.Tiering.Archive.Delete(fmt.Sprintf("%d%s", a.BaseOffset, ext)); err != nil {
+				return err
+			}
+		}
+	}
+	return l.discard()
+}
+
+// Deletes the log's local files, which the log must already be closed for
+func (l *Log) discard() error {
+	l.manifest = manifest{}
 	return os.RemoveAll(l.Dir)
 }
 
-// Resets the log by removing all of its contents and creating a new instance of log
+// Resets the log by removing all of its contents on local disk and creating a new instance of log
+// Segments uploaded to the archive are left there, the log just no longer reads them
 func (l *Log) Reset() error {
-	if err := l.Remove(); err != nil {
+	return l.resetAt(l.Config.Segment.InitialOffset)
+}
+
+// Resets the log like Reset, but with the first record appended getting the offset
+func (l *Log) resetAt(offset uint64) error {
+	if l.readOnly {
+		return errReadOnly
+	}
+	if err := l.Close(); err != nil {
 		return err
 	}
-	var err error
-	new, err := NewLog(l.Dir, l.Config)
+	if err := l.discard(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.segments = nil
+	l.activeSegment = nil
+	l.group = nil
+	l.pending = 0
+	return l.setup(offset)
+}
+
+// Appends the record at its own offset, which may leave a gap after the last record like compaction does, so a
+// restored snapshot keeps the offsets of the log it was taken from
+// The record keeps the link it was written with, since the record it links to may be one compaction removed
+func (l *Log) appendAt(record *api.Record) error {
+	l.mu.Lock()
+	off, err := l.activeSegment.appendAt(record.Offset, record)
+	if err == nil {
+		l.linked(record)
+		if l.activeSegment.IsMaxed() {
+			if err = l.seal(); err == nil {
+				err = l.newSegment(off + 1)
+			}
+		}
+	}
 	if err != nil {
+		l.mu.Unlock()
 		return err
 	}
-	*l = *new
-	return nil
+	l.notify()
+	wait := l.commit(1)
+	l.mu.Unlock()
+	return wait()
+}
+
+// Moves the offset the next record appended gets up to the given one, as a restored snapshot ends where the log it
+// was taken from did even if compaction removed the records just before that
+func (l *Log) skipTo(offset uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	active := l.activeSegment
+	if active.nextOffset >= offset {
+		return nil
+	}
+	if active.nextOffset == active.baseOffset {
+		// there's nothing in it to keep, and a segment's base offset can't change
+		if err := active.Remove(); err != nil {
+			return err
+		}
+		l.segments = l.segments[:len(l.segments)-1]
+	} else if err := l.seal(); err != nil {
+		return err
+	}
+	return l.newSegment(offset)
 }
 
-// Returns an io.Reader to read the whole log
+// Returns an io.Reader to read the segments on local disk
 func (l *Log) Reader() io.Reader {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	readers := make([]io.Reader, len(l.segments))
 	for i, segment := range l.segments {
-		readers[i] = segment.store
+		// read the records in each store, after its header, rather than from wherever the file's offset was left by the
+		// last append
+		_ = segment.store.Flush()
+		readers[i] = io.NewSectionReader(
+			segment.store.File,
+			segmentHeaderWidth,
+			int64(segment.store.size)-segmentHeaderWidth,
+		)
 	}
 	return io.MultiReader(readers...)
 }
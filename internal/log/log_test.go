@@ -1,9 +1,17 @@
 package log_test
 
 import (
+	"encoding/binary"
+	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -19,6 +27,7 @@ func TestLog(t *testing.T) {
 		"lowest offset":                     testLowestOffset,
 		"highest offset":                    testHighestOffset,
 		"truncate":                          testTruncate,
+		"corrupt record error":              testCorruptRecordErr,
 	} {
 		t.Run(scenario, func(t *testing.T) {
 			dir, err := ioutil.TempDir("", "store-test")
@@ -114,3 +123,923 @@ func testTruncate(t *testing.T, log *log.Log) {
 	_, err = log.Read(0)
 	require.IsType(t, api.ErrOffsetOutOfRange{}, err)
 }
+
+func testCorruptRecordErr(t *testing.T, o *log.Log) {
+	append := &api.Record{
+		Value: []byte("hello world"),
+	}
+	off, err := o.Append(append)
+	require.NoError(t, err)
+	_, err = o.Append(append)
+	require.NoError(t, err)
+	require.NoError(t, o.Close())
+
+	// flip the last byte of the first record's content on disk, which comes after the 22-byte segment header
+	f, err := os.OpenFile(filepath.Join(o.Dir, "0.store"), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	size := make([]byte, 8)
+	_, err = f.ReadAt(size, 22)
+	require.NoError(t, err)
+	last := int64(22+12+binary.BigEndian.Uint64(size)) - 1
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, last)
+	require.NoError(t, err)
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b, last)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	n, err := log.NewLog(o.Dir, o.Config)
+	require.NoError(t, err)
+	read, err := n.Read(off)
+	require.Nil(t, read)
+	require.Equal(t, api.ErrCorruptRecord{Offset: off}, err)
+}
+
+func TestRecoverTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recover-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxIndexBytes = 1024
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	append := &api.Record{
+		Value: []byte("hello world"),
+	}
+	for i := 0; i < 3; i++ {
+		_, err := l.Append(append)
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Close())
+
+	// simulate a crash mid-append: the store only got part of the fourth record,
+	// but its index entry made it into the index, which was never trimmed
+	storeFile := filepath.Join(dir, "0.store")
+	fi, err := os.Stat(storeFile)
+	require.NoError(t, err)
+	f, err := os.OpenFile(storeFile, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	torn := []byte{0, 0, 0, 0, 0, 0, 0, 100, 1, 2, 3, 4, 'h', 'e', 'l'}
+	_, err = f.Write(torn)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	indexFile := filepath.Join(dir, "0.index")
+	require.NoError(t, os.Truncate(indexFile, int64(c.Segment.MaxIndexBytes)))
+	f, err = os.OpenFile(indexFile, os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	entry := []byte{0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, byte(fi.Size())}
+	_, err = f.WriteAt(entry, 3*int64(len(entry)))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	n, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	require.Equal(t, log.Recovery{
+		BaseOffset:       0,
+		NextOffset:       3,
+		DiscardedBytes:   uint64(len(torn)),
+		DiscardedEntries: 1,
+	}, n.Recovery())
+
+	off, err := n.Append(append)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), off)
+	for i := uint64(0); i <= off; i++ {
+		read, err := n.Read(i)
+		require.NoError(t, err)
+		require.Equal(t, append.Value, read.Value)
+	}
+	require.NoError(t, n.Close())
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "read-only-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 256
+	c.Segment.MaxIndexBytes = 1024
+	c.Chain.Enabled = true
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Close())
+
+	// a crash cut off the next record, and left the active segment's index padded out
+	stores, err := filepath.Glob(filepath.Join(dir, "*.store"))
+	require.NoError(t, err)
+	sort.Strings(stores)
+	active := stores[len(stores)-1]
+	f, err := os.OpenFile(active, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	torn := []byte{0, 0, 0, 0, 0, 0, 0, 100, 1, 2, 3, 4, 'r', 'e', 'c'}
+	_, err = f.Write(torn)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	index := strings.TrimSuffix(active, ".store") + ".index"
+	require.NoError(t, os.Truncate(index, int64(c.Segment.MaxIndexBytes)))
+	files := func() map[string][]byte {
+		contents := map[string][]byte{}
+		require.NoError(t, filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				contents[name], err = ioutil.ReadFile(name)
+			}
+			return err
+		}))
+		return contents
+	}
+	before := files()
+
+	r, err := log.OpenReadOnly(dir, c)
+	require.NoError(t, err)
+	recovery := r.Recovery()
+	require.Equal(t, uint64(len(torn)), recovery.DiscardedBytes)
+	require.Equal(t, uint64(10), recovery.NextOffset)
+	for i := uint64(0); i < 10; i++ {
+		record, err := r.Read(i)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("record-%d", i)), record.Value)
+	}
+	_, err = r.Read(10)
+	require.Equal(t, api.ErrOffsetOutOfRange{Offset: 10}, err)
+	v, err := r.Verify(0, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), v.Records)
+	_, err = r.Append(&api.Record{Value: []byte("record-10")})
+	require.Error(t, err)
+	require.NoError(t, r.Close())
+
+	// the torn record is still there for the node to discard
+	require.Equal(t, before, files())
+}
+
+func TestSyncPolicies(t *testing.T) {
+	for name, policy := range map[string]log.SyncPolicy{
+		"none":   log.SyncNone,
+		"always": log.SyncAlways,
+		"batch":  log.SyncBatch,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "sync-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c := log.Config{}
+			c.Segment.Sync = policy
+			c.Segment.SyncEveryRecords = 4
+			c.Segment.SyncInterval = 5 * time.Millisecond
+			l, err := log.NewLog(dir, c)
+			require.NoError(t, err)
+
+			// concurrent appends should all return, sharing fsyncs when group committing
+			var wg sync.WaitGroup
+			offsets := make(chan uint64, 10)
+			for i := 0; i < cap(offsets); i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					off, err := l.Append(&api.Record{Value: []byte("hello world")})
+					require.NoError(t, err)
+					offsets <- off
+				}()
+			}
+			wg.Wait()
+			close(offsets)
+
+			seen := map[uint64]bool{}
+			for off := range offsets {
+				seen[off] = true
+			}
+			require.Equal(t, cap(offsets), len(seen))
+			require.NoError(t, l.Close())
+
+			// everything that was acknowledged made it to disk
+			l, err = log.NewLog(dir, c)
+			require.NoError(t, err)
+			for off := range seen {
+				_, err := l.Read(off)
+				require.NoError(t, err)
+			}
+			require.NoError(t, l.Close())
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	for scenario, fn := range map[string]func(c *log.Config){
+		"max bytes": func(c *log.Config) {
+			c.Retention.MaxBytes = 64
+		},
+		"max age": func(c *log.Config) {
+			c.Retention.MaxAge = time.Nanosecond
+		},
+	} {
+		t.Run(scenario, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "retention-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c := log.Config{}
+			c.Segment.MaxStoreBytes = 80
+			c.Retention.CheckInterval = 10 * time.Millisecond
+			fn(&c)
+			l, err := log.NewLog(dir, c)
+			require.NoError(t, err)
+			defer l.Close()
+
+			// two records fill a segment, so this leaves three full segments and an empty active one
+			for i := 0; i < 6; i++ {
+				_, err := l.Append(&api.Record{Value: []byte("hello world")})
+				require.NoError(t, err)
+			}
+
+			require.Eventually(t, func() bool {
+				off, err := l.LowestOffset()
+				require.NoError(t, err)
+				return off >= 4
+			}, time.Second, 10*time.Millisecond)
+
+			// the active segment is never deleted
+			off, err := l.Append(&api.Record{Value: []byte("hello world")})
+			require.NoError(t, err)
+			require.Equal(t, uint64(6), off)
+		})
+	}
+
+	t.Run("min offset", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "retention-test")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		c := log.Config{}
+		c.Segment.MaxStoreBytes = 80
+		c.Retention.MaxAge = time.Nanosecond
+		c.Retention.MinOffset = 3
+		c.Retention.CheckInterval = 10 * time.Millisecond
+		l, err := log.NewLog(dir, c)
+		require.NoError(t, err)
+		defer l.Close()
+
+		for i := 0; i < 6; i++ {
+			_, err := l.Append(&api.Record{Value: []byte("hello world")})
+			require.NoError(t, err)
+		}
+
+		// the segment holding offsets 2 and 3 has to stay
+		require.Eventually(t, func() bool {
+			off, err := l.LowestOffset()
+			require.NoError(t, err)
+			return off == 2
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		off, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), off)
+	})
+}
+
+func TestCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compaction-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 3 * 16
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+
+	// three records to a segment: [a b a] [a c b] [] with the last segment active
+	// a-3, c-4 and b-5 are the latest records for their keys, so only the first segment changes
+	keys := []string{"a", "b", "a", "a", "c", "b"}
+	for i, key := range keys {
+		_, err := l.Append(&api.Record{
+			Key:   []byte(key),
+			Value: []byte(fmt.Sprintf("%s-%d", key, i)),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Compact())
+
+	// a superseded record reads as the next record kept in its segment
+	for offset, want := range map[uint64]uint64{
+		0: 2, // a-0 is gone, a-2 stays as the last record of the segment
+		1: 2, // b-1 is gone
+		2: 2,
+		3: 3,
+		4: 4,
+		5: 5,
+	} {
+		record, err := l.Read(offset)
+		require.NoError(t, err)
+		require.Equal(t, want, record.Offset)
+		require.Equal(t, fmt.Sprintf("%s-%d", keys[want], want), string(record.Value))
+	}
+
+	// offsets are kept, so the log carries on where it was, including after reopening it
+	require.NoError(t, l.Close())
+	l, err = log.NewLog(dir, c)
+	require.NoError(t, err)
+	off, err := l.HighestOffset()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), off)
+	record, err := l.Read(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), record.Offset)
+	off, err = l.Append(&api.Record{Value: []byte("no key")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), off)
+	require.NoError(t, l.Close())
+}
+
+func TestCodecs(t *testing.T) {
+	value := []byte(`{"sender_id":"8c5b0a5e","receiver_id":"1f0a7d3c","amount":"100.00"}`)
+	value = append(value, value...)
+
+	sizes := map[log.Codec]int64{}
+	for _, codec := range []log.Codec{log.CodecNone, log.CodecGzip, log.CodecSnappy} {
+		t.Run(codec.String(), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "codec-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c := log.Config{}
+			c.Segment.MaxStoreBytes = 1 << 16
+			c.Segment.Codec = codec
+			l, err := log.NewLog(dir, c)
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				_, err := l.Append(&api.Record{Value: value})
+				require.NoError(t, err)
+			}
+			require.NoError(t, l.Close())
+			fi, err := os.Stat(filepath.Join(dir, "0.store"))
+			require.NoError(t, err)
+			sizes[codec] = fi.Size()
+
+			// switching codecs leaves the records already written readable
+			for _, next := range []log.Codec{log.CodecNone, log.CodecGzip, log.CodecSnappy} {
+				c.Segment.Codec = next
+				l, err = log.NewLog(dir, c)
+				require.NoError(t, err)
+				_, err := l.Append(&api.Record{Value: value})
+				require.NoError(t, err)
+				require.NoError(t, l.Close())
+			}
+
+			l, err = log.NewLog(dir, c)
+			require.NoError(t, err)
+			for off := uint64(0); off < 6; off++ {
+				record, err := l.Read(off)
+				require.NoError(t, err)
+				require.Equal(t, value, record.Value)
+			}
+			require.NoError(t, l.Close())
+		})
+	}
+	require.Less(t, sizes[log.CodecGzip], sizes[log.CodecNone])
+	require.Less(t, sizes[log.CodecSnappy], sizes[log.CodecNone])
+}
+
+func TestOffsetForTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "time-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 64
+	c.Segment.TimeIndexIntervalBytes = 32
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+
+	// producers set their own timestamps, so they aren't always in order
+	start := time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC)
+	minutes := []int{0, 10, 20, 50, 30, 40, 60, 70, 80, 90}
+	for _, m := range minutes {
+		_, err := l.Append(&api.Record{
+			Value:     []byte("hello world"),
+			Timestamp: start.Add(time.Duration(m) * time.Minute).UnixNano(),
+		})
+		require.NoError(t, err)
+	}
+
+	check := func(l *log.Log) {
+		for m, want := range map[int]uint64{
+			-5:  0,
+			0:   0,
+			10:  1,
+			25:  3,
+			45:  3,
+			55:  6,
+			90:  9,
+			100: uint64(len(minutes)),
+		} {
+			off, err := l.OffsetForTime(start.Add(time.Duration(m) * time.Minute))
+			require.NoError(t, err)
+			require.Equal(t, want, off, "minute %d", m)
+		}
+	}
+	check(l)
+
+	// the time indexes survive a restart
+	require.NoError(t, l.Close())
+	l, err = log.NewLog(dir, c)
+	require.NoError(t, err)
+	check(l)
+	require.NoError(t, l.Close())
+}
+
+func TestIterator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iterator-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 64
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	defer l.Close()
+
+	for i := 0; i < 10; i++ {
+		_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+
+	collect := func(it *log.Iterator) []uint64 {
+		var offsets []uint64
+		for it.Next() {
+			record := it.Record()
+			require.Equal(t, fmt.Sprintf("record-%d", record.Offset), string(record.Value))
+			offsets = append(offsets, record.Offset)
+		}
+		require.NoError(t, it.Err())
+		return offsets
+	}
+
+	t.Run("range across segments", func(t *testing.T) {
+		require.Equal(t, []uint64{3, 4, 5, 6}, collect(l.Iterator(3, 7, 0)))
+		require.Equal(t, []uint64{8, 9}, collect(l.Iterator(8, math.MaxUint64, 0)))
+		require.Empty(t, collect(l.Iterator(10, math.MaxUint64, 0)))
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		// framed records take up a little over 30 bytes, so two fit
+		require.Equal(t, []uint64{0, 1}, collect(l.Iterator(0, math.MaxUint64, 70)))
+		// the first record is returned even if it's over the budget
+		require.Equal(t, []uint64{5}, collect(l.Iterator(5, math.MaxUint64, 1)))
+	})
+
+	t.Run("follows appends", func(t *testing.T) {
+		it := l.Iterator(9, math.MaxUint64, 0)
+		require.Equal(t, []uint64{9}, collect(it))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 10; i < 20; i++ {
+				_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+				require.NoError(t, err)
+			}
+		}()
+		// an iterator isn't safe to share between goroutines, so wait for appends and read them from this one
+		var offsets []uint64
+		for timeout := time.After(time.Second); len(offsets) < 10; {
+			select {
+			case <-it.Wait():
+				offsets = append(offsets, collect(it)...)
+			case <-timeout:
+				t.Fatalf("got %d records before timing out", len(offsets))
+			}
+		}
+		wg.Wait()
+		require.Len(t, offsets, 10)
+		for i, off := range offsets {
+			require.Equal(t, uint64(10+i), off)
+		}
+	})
+
+	t.Run("after retention", func(t *testing.T) {
+		it := l.Iterator(0, math.MaxUint64, 0)
+		require.True(t, it.Next())
+		require.NoError(t, l.Truncate(5))
+		// the iterator carries on from the lowest offset that's left
+		offsets := collect(it)
+		lowest, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.Equal(t, lowest, offsets[0])
+		require.Equal(t, uint64(19), offsets[len(offsets)-1])
+	})
+
+	t.Run("wakes when the log's closed", func(t *testing.T) {
+		it := l.Iterator(20, math.MaxUint64, 0)
+		require.False(t, it.Next())
+		wait := it.Wait()
+		select {
+		case <-wait:
+			t.Fatal("woke before anything happened")
+		default:
+		}
+		require.NoError(t, l.Close())
+		<-wait
+		require.False(t, it.Next())
+		require.Error(t, it.Err())
+	})
+}
+
+func TestReadAcrossSegments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "read-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// 1KiB segments hold a few records each, so this rolls hundreds of them
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	const n = 2000
+	value := make([]byte, 100)
+	for i := uint64(0); i < n; i++ {
+		binary.BigEndian.PutUint64(value, i)
+		off, err := l.Append(&api.Record{Value: value})
+		require.NoError(t, err)
+		require.Equal(t, i, off)
+	}
+
+	check := func(t *testing.T, l *log.Log, from uint64) {
+		for off := from; off < n; off++ {
+			record, err := l.Read(off)
+			require.NoError(t, err)
+			require.Equal(t, off, record.Offset)
+			require.Equal(t, off, binary.BigEndian.Uint64(record.Value))
+		}
+		_, err := l.Read(n)
+		require.IsType(t, api.ErrOffsetOutOfRange{}, err)
+	}
+
+	t.Run("many rolled segments", func(t *testing.T) {
+		files, err := filepath.Glob(filepath.Join(dir, "*.store"))
+		require.NoError(t, err)
+		require.Greater(t, len(files), 200)
+		check(t, l, 0)
+	})
+
+	t.Run("after truncation", func(t *testing.T) {
+		require.NoError(t, l.Truncate(999))
+		lowest, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.Greater(t, lowest, uint64(0))
+		for off := uint64(0); off < lowest; off++ {
+			_, err := l.Read(off)
+			require.IsType(t, api.ErrOffsetOutOfRange{}, err)
+		}
+		check(t, l, lowest)
+	})
+
+	t.Run("after reopening", func(t *testing.T) {
+		lowest, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.NoError(t, l.Close())
+		l, err = log.NewLog(dir, c)
+		require.NoError(t, err)
+		reopened, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.Equal(t, lowest, reopened)
+		check(t, l, lowest)
+	})
+	require.NoError(t, l.Close())
+}
+
+func BenchmarkRead(b *testing.B) {
+	dir, err := ioutil.TempDir("", "read-bench")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	l, err := log.NewLog(dir, c)
+	require.NoError(b, err)
+	defer l.Close()
+	const n = 5000
+	for i := 0; i < n; i++ {
+		_, err := l.Append(&api.Record{Value: make([]byte, 100)})
+		require.NoError(b, err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := l.Read(uint64(i % n)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAppendBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 64
+	c.Segment.Codec = log.CodecGzip
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+
+	_, err = l.Append(&api.Record{Value: []byte("record-0")})
+	require.NoError(t, err)
+
+	// the batch goes into the active segment as a whole, and the log rolls over to a new segment after it
+	var batch []*api.Record
+	for i := 1; i < 8; i++ {
+		record := &api.Record{Value: []byte(fmt.Sprintf("record-%d", i))}
+		if i == 2 || i == 3 {
+			record.Key = []byte("key")
+		}
+		batch = append(batch, record)
+	}
+	offsets, err := l.AppendBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, offsets)
+	stores, err := filepath.Glob(filepath.Join(dir, "*.store"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "0.store"), filepath.Join(dir, "8.store")}, stores)
+
+	offsets, err = l.AppendBatch(nil)
+	require.NoError(t, err)
+	require.Empty(t, offsets)
+
+	require.NoError(t, l.Close())
+	l, err = log.NewLog(dir, c)
+	require.NoError(t, err)
+	for off := uint64(0); off < 8; off++ {
+		record, err := l.Read(off)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("record-%d", off), string(record.Value))
+	}
+	off, err := l.Append(&api.Record{Value: []byte("record-8")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), off)
+
+	// compaction drops record-2 from the batch and keeps the rest of it
+	require.NoError(t, l.Compact())
+	record, err := l.Read(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), record.Offset)
+	var read []uint64
+	it := l.Iterator(0, 9, 0)
+	for it.Next() {
+		read = append(read, it.Record().Offset)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []uint64{0, 1, 3, 4, 5, 6, 7, 8}, read)
+	require.NoError(t, l.Close())
+}
+
+func TestSparseIndex(t *testing.T) {
+	indexSizes := map[string]int64{}
+	for mode, interval := range map[string]uint64{"dense": 0, "sparse": 256} {
+		t.Run(mode, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "sparse-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			c := log.Config{}
+			c.Segment.MaxStoreBytes = 4096
+			c.Segment.IndexIntervalBytes = interval
+			l, err := log.NewLog(dir, c)
+			require.NoError(t, err)
+
+			start := time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC)
+			const n = 300
+			for i := 0; i < n; i++ {
+				_, err := l.Append(&api.Record{
+					Key:       []byte(fmt.Sprintf("key-%d", i%100)),
+					Value:     []byte(fmt.Sprintf("record-%d", i)),
+					Timestamp: start.Add(time.Duration(i) * time.Second).UnixNano(),
+				})
+				require.NoError(t, err)
+			}
+
+			check := func(l *log.Log) {
+				for off := uint64(0); off < n; off++ {
+					record, err := l.Read(off)
+					require.NoError(t, err)
+					require.Equal(t, fmt.Sprintf("record-%d", off), string(record.Value))
+				}
+				it := l.Iterator(0, math.MaxUint64, 0)
+				for off := uint64(0); off < n; off++ {
+					require.True(t, it.Next())
+					require.Equal(t, off, it.Record().Offset)
+				}
+				require.False(t, it.Next())
+				require.NoError(t, it.Err())
+				off, err := l.OffsetForTime(start.Add(150 * time.Second))
+				require.NoError(t, err)
+				require.Equal(t, uint64(150), off)
+			}
+			check(l)
+
+			// reopening recovers the active segment's index in the same mode
+			require.NoError(t, l.Close())
+			l, err = log.NewLog(dir, c)
+			require.NoError(t, err)
+			check(l)
+			off, err := l.Append(&api.Record{Value: []byte("next")})
+			require.NoError(t, err)
+			require.Equal(t, uint64(n), off)
+
+			// compaction leaves gaps, and reads of a removed offset get the next record kept
+			require.NoError(t, l.Compact())
+			for off := uint64(0); off < n; off++ {
+				record, err := l.Read(off)
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, record.Offset, off)
+				require.Equal(t, fmt.Sprintf("record-%d", record.Offset), string(record.Value))
+			}
+			require.NoError(t, l.Close())
+
+			files, err := filepath.Glob(filepath.Join(dir, "*.index"))
+			require.NoError(t, err)
+			for _, file := range files {
+				fi, err := os.Stat(file)
+				require.NoError(t, err)
+				indexSizes[mode] += fi.Size()
+			}
+		})
+	}
+	require.Less(t, indexSizes["sparse"]*4, indexSizes["dense"])
+}
+
+func TestTiering(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tiering-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archiveDir, err := ioutil.TempDir("", "tiering-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(archiveDir)
+	archive, err := log.NewDirArchive(archiveDir)
+	require.NoError(t, err)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Tiering.Archive = archive
+	c.Tiering.LocalBytes = 2048
+	// small enough that fetching one archived segment evicts another from the cache
+	c.Tiering.CacheBytes = 1024
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+
+	start := time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC)
+	const n = 200
+	for i := 0; i < n; i++ {
+		_, err := l.Append(&api.Record{
+			Value:     []byte(fmt.Sprintf("record-%d", i)),
+			Timestamp: start.Add(time.Duration(i) * time.Second).UnixNano(),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Offload())
+
+	stores := func(dir string) int {
+		files, err := filepath.Glob(filepath.Join(dir, "*.store"))
+		require.NoError(t, err)
+		return len(files)
+	}
+	local, archived := stores(l.Dir), stores(archive.Dir)
+	// every closed segment was uploaded, and all but the newest few were deleted locally
+	require.Greater(t, archived, local)
+	require.LessOrEqual(t, local, 4)
+
+	check := func(l *log.Log) {
+		lowest, err := l.LowestOffset()
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), lowest)
+		for _, off := range []uint64{0, 1, n / 2, 3, n - 1} {
+			record, err := l.Read(off)
+			require.NoError(t, err)
+			require.Equal(t, off, record.Offset)
+			require.Equal(t, fmt.Sprintf("record-%d", off), string(record.Value))
+		}
+		it := l.Iterator(0, math.MaxUint64, 0)
+		for off := uint64(0); off < n; off++ {
+			require.True(t, it.Next())
+			require.Equal(t, off, it.Record().Offset)
+		}
+		require.False(t, it.Next())
+		require.NoError(t, it.Err())
+		off, err := l.OffsetForTime(start.Add(10 * time.Second))
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), off)
+	}
+	check(l)
+
+	// the manifest remembers which segments are only in the archive
+	require.NoError(t, l.Close())
+	l, err = log.NewLog(l.Dir, c)
+	require.NoError(t, err)
+	require.Equal(t, local, stores(l.Dir))
+	check(l)
+
+	// deleting the start of the log deletes it from the archive too
+	require.NoError(t, l.Truncate(n/2))
+	lowest, err := l.LowestOffset()
+	require.NoError(t, err)
+	require.Greater(t, lowest, uint64(0))
+	_, err = l.Read(0)
+	require.Equal(t, api.ErrOffsetOutOfRange{Offset: 0}, err)
+	require.Less(t, stores(archive.Dir), archived)
+
+	require.NoError(t, l.Remove())
+	require.Equal(t, 0, stores(archive.Dir))
+}
+
+func TestResetKeepsArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tiering-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archiveDir, err := ioutil.TempDir("", "tiering-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(archiveDir)
+	archive, err := log.NewDirArchive(archiveDir)
+	require.NoError(t, err)
+
+	c := log.Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Tiering.Archive = archive
+	c.Tiering.LocalBytes = 2048
+	l, err := log.NewLog(dir, c)
+	require.NoError(t, err)
+	defer l.Close()
+	for i := 0; i < 200; i++ {
+		_, err := l.Append(&api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Offload())
+	archived, err := filepath.Glob(filepath.Join(archiveDir, "*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, archived)
+
+	// resetting the log, as restoring a snapshot does, only clears local disk
+	require.NoError(t, l.Reset())
+	_, err = l.Read(0)
+	require.Equal(t, api.ErrOffsetOutOfRange{Offset: 0}, err)
+	left, err := filepath.Glob(filepath.Join(archiveDir, "*"))
+	require.NoError(t, err)
+	require.Equal(t, archived, left)
+}
+
+func TestTopics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a log written before there were topics becomes the default topic
+	l, err := log.NewLog(dir, log.Config{})
+	require.NoError(t, err)
+	_, err = l.Append(&api.Record{Value: []byte("legacy")})
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	topics, err := log.NewTopics(dir, log.Config{})
+	require.NoError(t, err)
+	record, err := topics.Read("", 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte("legacy"), record.Value)
+
+	require.NoError(t, topics.CreateTopic("payments"))
+	require.Equal(t, api.ErrTopicExists{Topic: "payments"}, topics.CreateTopic("payments"))
+	require.Error(t, topics.CreateTopic("../payments"))
+	off, err := topics.Append("payments", &api.Record{Value: []byte("payment")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), off)
+	_, err = topics.Append("orders", &api.Record{Value: []byte("order")})
+	require.Equal(t, api.ErrTopicNotFound{Topic: "orders"}, err)
+
+	// topics are opened again on restart
+	require.NoError(t, topics.Close())
+	topics, err = log.NewTopics(dir, log.Config{})
+	require.NoError(t, err)
+	names, err := topics.ListTopics()
+	require.NoError(t, err)
+	require.Equal(t, []string{log.DefaultTopic, "payments"}, names)
+	record, err = topics.Read("payments", 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte("payment"), record.Value)
+
+	require.Error(t, topics.DeleteTopic(log.DefaultTopic))
+	require.NoError(t, topics.DeleteTopic("payments"))
+	_, err = os.Stat(filepath.Join(dir, "payments"))
+	require.True(t, os.IsNotExist(err))
+	_, err = topics.Read("payments", 0)
+	require.Equal(t, api.ErrTopicNotFound{Topic: "payments"}, err)
+	require.NoError(t, topics.Close())
+}
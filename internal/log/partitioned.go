@@ -0,0 +1,277 @@
+package log
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	api "ledger/api/v1"
+)
+
+// Partition is one partition of every topic
+// Topics keeps a partition on a single server, DistributedLog replicates one across the cluster
+type Partition interface {
+	Append(topic string, record *api.Record) (uint64, error)
+	AppendBatch(topic string, records []*api.Record) ([]uint64, error)
+	// appends the records the producer hasn't appended yet, duplicates get the offsets they were first appended at
+	AppendFrom(topic string, producer Producer, records []*api.Record) ([]uint64, error)
+	Read(topic string, offset uint64) (*api.Record, error)
+	OffsetForTime(topic string, t time.Time) (uint64, error)
+	Iterator(topic string, from, to, maxBytes uint64) (*Iterator, error)
+	CreateTopic(name string) error
+	DeleteTopic(name string) error
+	ListTopics() ([]string, error)
+	CommitOffset(topic, group string, offset uint64) error
+	// returns api.ErrOffsetNotCommitted if the group hasn't committed an offset for the topic
+	CommittedOffset(topic, group string) (uint64, error)
+	// returns the offset the topic's next record gets
+	EndOffset(topic string) (uint64, error)
+	// blocks until reads from this server meet the consistency level, or returns api.ErrNotLeader if only the
+	// partition's leader can serve it
+	WaitForConsistency(c api.Consistency) error
+}
+
+var (
+	_ Partition = (*Topics)(nil)
+	_ Partition = (*DistributedLog)(nil)
+)
+
+// Returns the partition records with the key go to, so records with the same key stay in order
+func PartitionFor(key []byte, partitions uint32) uint32 {
+	h := fnv.New32a()
+	h.Write(key)
+	return h.Sum32() % partitions
+}
+
+// Partitions kept on this server only, without Raft, e.g. for tests and tools
+type LocalPartitions []*Topics
+
+func (p LocalPartitions) Partitions() uint32 {
+	return uint32(len(p))
+}
+
+func (p LocalPartitions) Partition(partition uint32) (Partition, error) {
+	if partition >= uint32(len(p)) {
+		return nil, api.ErrPartitionNotFound{Partition: partition, Partitions: uint32(len(p))}
+	}
+	return p[partition], nil
+}
+
+// Topics split into partitions, each replicated by a Raft group of its own, so each partition's leader takes a share
+// of the writes
+//
+// Every server is in every partition's group. The first partition keeps the data directory's log and Raft
+// directories, so a server started with more partitions than before keeps its records, and the others are stored
+// under partitions/<partition>, in the data directory and in the archive.
+type PartitionedLog struct {
+	config     Config
+	partitions []*DistributedLog
+	// closed to stop balancing leaders
+	stop       chan struct{}
+	background sync.WaitGroup
+}
+
+func NewPartitionedLog(dataDir string, config Config) (*PartitionedLog, error) {
+	n := config.Raft.Partitions
+	if n == 0 {
+		n = 1
+	}
+	config = withDefaults(config)
+	l := &PartitionedLog{
+		config: config,
+		stop:   make(chan struct{}),
+	}
+	for p := uint32(0); p < n; p++ {
+		dir := dataDir
+		c := config
+		c.Logger = config.Logger.With("partition", p)
+		if p != 0 {
+			dir = filepath.Join(dataDir, "partitions", strconv.FormatUint(uint64(p), 10))
+			c.Raft.StreamLayer = config.Raft.StreamLayer.Partition(p)
+			c.Raft.Policy = nil
+			if c.Tiering.Archive != nil {
+				// partitions share the archive the same way topics do
+				c.Tiering.Archive = prefixArchive{c.Tiering.Archive, fmt.Sprintf("partitions/%d/", p)}
+			}
+		}
+		partition, err := NewDistributedLog(dir, c)
+		if err != nil {
+			_ = l.closePartitions()
+			return nil, err
+		}
+		l.partitions = append(l.partitions, partition)
+	}
+	if n > 1 {
+		interval := config.Raft.BalanceInterval
+		if interval == 0 {
+			interval = 10 * time.Second
+		}
+		l.background.Add(1)
+		go l.balanceLoop(interval)
+	}
+	return l, nil
+}
+
+func (l *PartitionedLog) Partitions() uint32 {
+	return uint32(len(l.partitions))
+}
+
+func (l *PartitionedLog) Partition(partition uint32) (Partition, error) {
+	if partition >= l.Partitions() {
+		return nil, api.ErrPartitionNotFound{Partition: partition, Partitions: l.Partitions()}
+	}
+	return l.partitions[partition], nil
+}
+
+// Adds the server to every partition this server leads
+// The other partitions' leaders add it to theirs, since every server hears about servers joining
+func (l *PartitionedLog) Join(id, addr string) error {
+	return l.leading(func(partition *DistributedLog) error {
+		return partition.Join(id, addr)
+	})
+}
+
+// The first partition replicates the ACL policy for the whole cluster
+func (l *PartitionedLog) AddRule(rule []string) error {
+	return l.partitions[0].AddRule(rule)
+}
+
+func (l *PartitionedLog) RemoveRule(rule []string) error {
+	return l.partitions[0].RemoveRule(rule)
+}
+
+func (l *PartitionedLog) Rules() [][]string {
+	return l.partitions[0].Rules()
+}
+
+// Removes the server from every partition this server leads
+func (l *PartitionedLog) Leave(id, addr string) error {
+	return l.leading(func(partition *DistributedLog) error {
+		return partition.Leave(id, addr)
+	})
+}
+
+// Calls fn with each partition this server leads, and returns raft.ErrNotLeader if it doesn't lead any
+func (l *PartitionedLog) leading(fn func(*DistributedLog) error) error {
+	err := raft.ErrNotLeader
+	for _, partition := range l.partitions {
+		if partition.raft.State() != raft.Leader {
+			continue
+		}
+		if err = fn(partition); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Blocks til every partition has elected a leader or times out
+func (l *PartitionedLog) WaitForLeader(timeout time.Duration) error {
+	timeoutc := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-timeoutc:
+			return fmt.Errorf("leader election timed out")
+		case <-ticker.C:
+			elected := true
+			for _, partition := range l.partitions {
+				elected = elected && partition.raft.Leader() != ""
+			}
+			if elected {
+				return nil
+			}
+		}
+	}
+}
+
+// Returns the servers in the cluster along with the partitions each leads
+func (l *PartitionedLog) GetServers() ([]*api.Server, error) {
+	servers, err := l.partitions[0].GetServers()
+	if err != nil {
+		return nil, err
+	}
+	for p, partition := range l.partitions {
+		leader := partition.raft.Leader()
+		for _, server := range servers {
+			if raft.ServerAddress(server.RpcAddr) == leader {
+				server.LeaderOf = append(server.LeaderOf, uint32(p))
+			}
+		}
+	}
+	return servers, nil
+}
+
+// Hands each partition's leadership to the server it's assigned to every interval, until the log is closed
+func (l *PartitionedLog) balanceLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			for p, partition := range l.partitions {
+				if err := partition.balance(uint32(p)); err != nil {
+					l.config.Logger.Error("balancing leader", "partition", p, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// If this server leads the partition but the partition's assigned to another server, hands leadership to that server
+//
+// Partitions are assigned to the voters in order of their IDs, round robin, so every server works out the same
+// assignment and each leads its share of the partitions
+func (l *DistributedLog) balance(partition uint32) error {
+	if l.raft.State() != raft.Leader {
+		return nil
+	}
+	future := l.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	var voters []raft.Server
+	for _, server := range future.Configuration().Servers {
+		if server.Suffrage == raft.Voter {
+			voters = append(voters, server)
+		}
+	}
+	if len(voters) == 0 {
+		return nil
+	}
+	sort.Slice(voters, func(i, j int) bool { return voters[i].ID < voters[j].ID })
+	assigned := voters[partition%uint32(len(voters))]
+	if assigned.ID == l.config.Raft.LocalID {
+		return nil
+	}
+	return l.raft.LeadershipTransferToServer(assigned.ID, assigned.Address).Error()
+}
+
+func (l *PartitionedLog) Close() error {
+	if l.stop != nil {
+		close(l.stop)
+		l.background.Wait()
+		l.stop = nil
+	}
+	return l.closePartitions()
+}
+
+// Closes the partitions in reverse, since closing the first partition closes the Raft listener they share
+func (l *PartitionedLog) closePartitions() error {
+	for i := len(l.partitions) - 1; i >= 0; i-- {
+		if err := l.partitions[i].Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+
+	api "ledger/api/v1"
+)
+
+var errNoPolicy = errors.New("log: the policy is only replicated by the first partition")
+
+// Policy holds the ACL rules added through Raft, which every server enforces on top of its policy file
+// Each rule is led by its type, p for a policy rule or g for a role, like a line of the policy file
+//
+// Only the first partition's Raft group replicates the policy
+type Policy struct {
+	mu    sync.Mutex
+	rules [][]string
+	// checks a rule before the leader proposes it, so rules this server couldn't enforce are never replicated
+	check func(rule []string) error
+	// called with every rule after each change, e.g. to load them into the authorizer
+	// the change is kept even if it returns an error, so every server holds the same rules whatever happens locally
+	onChange func(rules [][]string) error
+}
+
+func NewPolicy(check func(rule []string) error, onChange func(rules [][]string) error) *Policy {
+	return &Policy{check: check, onChange: onChange}
+}
+
+// Returns the rules in the order they were added
+func (p *Policy) Rules() [][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]string(nil), p.rules...)
+}
+
+// Returns an error if the rule can't be added
+func (p *Policy) validate(rule []string) error {
+	if p.check == nil {
+		return nil
+	}
+	return p.check(rule)
+}
+
+// Adding a rule that's already there does nothing
+// Returns onChange's error, the rule is added all the same
+func (p *Policy) add(rule []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.find(rule) != -1 {
+		return nil
+	}
+	return p.set(append(append([][]string(nil), p.rules...), rule))
+}
+
+// Returns api.ErrRuleNotFound if the rule isn't there, otherwise onChange's error, the rule is removed all the same
+func (p *Policy) remove(rule []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := p.find(rule)
+	if i == -1 {
+		return api.ErrRuleNotFound{Rule: rule}
+	}
+	return p.set(append(append([][]string(nil), p.rules[:i]...), p.rules[i+1:]...))
+}
+
+// Replaces the rules, returning onChange's error, the rules are replaced all the same
+func (p *Policy) replace(rules [][]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.set(rules)
+}
+
+// Returns the rule's position, or -1 if it's not there
+// Must be called with the lock held
+func (p *Policy) find(rule []string) int {
+	for i, r := range p.rules {
+		if reflect.DeepEqual(r, rule) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Replaces the rules and hands them to onChange
+// Must be called with the lock held
+func (p *Policy) set(rules [][]string) error {
+	p.rules = rules
+	if p.onChange != nil {
+		return p.onChange(rules)
+	}
+	return nil
+}
+
+// Returns the rules encoded for a snapshot
+func (p *Policy) snapshot() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return json.Marshal(p.rules)
+}
+
+// Decodes the rules a snapshot holds
+func decodeRules(b []byte) ([][]string, error) {
+	var rules [][]string
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	api "ledger/api/v1"
+)
+
+func TestPolicy(t *testing.T) {
+	var loaded [][]string
+	policy := NewPolicy(nil, func(rules [][]string) error {
+		for _, rule := range rules {
+			if rule[0] == "bad" {
+				return errors.New("bad rule")
+			}
+		}
+		loaded = rules
+		return nil
+	})
+	reader := []string{"g", "projection", "reader"}
+	writer := []string{"g", "billing", "writer"}
+
+	require.NoError(t, policy.add(reader))
+	require.NoError(t, policy.add(writer))
+	// adding a rule again does nothing
+	require.NoError(t, policy.add(reader))
+	require.Equal(t, [][]string{reader, writer}, policy.Rules())
+	require.Equal(t, policy.Rules(), loaded)
+
+	// a rule onChange refuses is still kept, so every server holds the same rules
+	bad := []string{"bad"}
+	require.Error(t, policy.add(bad))
+	require.Equal(t, [][]string{reader, writer, bad}, policy.Rules())
+	require.NoError(t, policy.remove(bad))
+	require.Equal(t, [][]string{reader, writer}, policy.Rules())
+
+	require.NoError(t, policy.remove(reader))
+	require.Equal(t, [][]string{writer}, policy.Rules())
+	require.Equal(t, api.ErrRuleNotFound{Rule: reader}, policy.remove(reader))
+
+	// the rules survive a snapshot, and replace the ones the restored policy had
+	dir, err := ioutil.TempDir("", "policy-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	topics, err := NewTopics(dir, Config{})
+	require.NoError(t, err)
+	defer topics.Close()
+	sink := &testSink{}
+	snap, err := (&fsm{topics: topics, policy: policy}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+
+	restored := NewPolicy(nil, nil)
+	require.NoError(t, restored.add(reader))
+	require.NoError(t, (&fsm{topics: topics, policy: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+	require.Equal(t, [][]string{writer}, restored.Rules())
+
+	// a snapshot without rules clears them
+	sink = &testSink{}
+	snap, err = (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+	require.NoError(t, (&fsm{topics: topics, policy: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+	require.Empty(t, restored.Rules())
+
+	// a rule this server can't enforce is applied all the same, and reported through the log's logger
+	var out bytes.Buffer
+	topics.Config.Logger = hclog.New(&hclog.LoggerOptions{Output: &out})
+	refusing := NewPolicy(nil, func(rules [][]string) error {
+		return errors.New("model has no such rule")
+	})
+	req, err := (&api.AddRuleRequest{Rule: &api.Rule{Type: "g", Values: writer[1:]}}).Marshal()
+	require.NoError(t, err)
+	require.Nil(t, (&fsm{topics: topics, policy: refusing}).applyAddRule(req))
+	require.Equal(t, [][]string{writer}, refusing.Rules())
+	require.Contains(t, out.String(), "enforcing replicated ACL rules: error=\"model has no such rule\"")
+}
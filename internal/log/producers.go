@@ -0,0 +1,103 @@
+package log
+
+import (
+	"time"
+
+	api "ledger/api/v1"
+)
+
+// Producer numbers the records it appends, so a record it retries isn't appended twice
+//
+// Each partition's FSM only sees the records appended to it, so a producer's sequence numbers are tracked, and have
+// to follow on with no gaps, separately for every topic and partition it appends to
+type Producer struct {
+	ID string
+	// sequence number of the first record, the records after it take the numbers that follow
+	Sequence uint64
+}
+
+const (
+	// a producer's duplicates get their original offsets as long as they're in its last this many runs of records
+	// appended together, older duplicates get api.ErrDuplicateSequence
+	producerRuns = 64
+	// producers that haven't appended for this long are forgotten
+	// It goes by the leader's clock when it proposed each batch rather than by the records' timestamps, which clients
+	// can set to anything, and rather than each server's own clock, so every server forgets a producer at the same
+	// point
+	producerExpiry = 7 * 24 * time.Hour
+)
+
+// What a topic remembers about a producer
+type producerState struct {
+	// last sequence number appended
+	Sequence uint64 `json:"sequence"`
+	// when the producer last appended, by the leader's clock
+	Timestamp int64 `json:"timestamp"`
+	// oldest first
+	Runs []sequenceRun `json:"runs"`
+}
+
+// Records with consecutive sequence numbers appended at consecutive offsets
+type sequenceRun struct {
+	Sequence uint64 `json:"sequence"`
+	Offset   uint64 `json:"offset"`
+	Count    uint64 `json:"count"`
+}
+
+// Returns the original offsets of the records at the start of the batch the producer already appended, the records
+// after them are new
+// Returns api.ErrOutOfOrderSequence if the batch would leave a gap after the producer's last record
+func (p *producerState) duplicates(id string, sequence uint64, n int) ([]uint64, error) {
+	if sequence > p.Sequence {
+		if sequence != p.Sequence+1 {
+			return nil, api.ErrOutOfOrderSequence{ProducerId: id, Sequence: sequence, Expected: p.Sequence + 1}
+		}
+		return nil, nil
+	}
+	var offsets []uint64
+	for seq := sequence; seq <= p.Sequence && len(offsets) < n; seq++ {
+		offset, ok := p.offset(seq)
+		if !ok {
+			return nil, api.ErrDuplicateSequence{ProducerId: id, Sequence: seq}
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// Returns the offset the record with the sequence number was appended at, if it's still remembered
+func (p *producerState) offset(sequence uint64) (uint64, bool) {
+	for _, run := range p.Runs {
+		if sequence >= run.Sequence && sequence < run.Sequence+run.Count {
+			return run.Offset + sequence - run.Sequence, true
+		}
+	}
+	return 0, false
+}
+
+// Remembers the records appended at consecutive offsets from the offset
+func (p *producerState) appended(sequence, offset, count uint64, timestamp int64) {
+	p.Sequence = sequence + count - 1
+	p.Timestamp = timestamp
+	if n := len(p.Runs); n > 0 {
+		// a producer that's the only one appending to the topic keeps extending its last run
+		last := &p.Runs[n-1]
+		if last.Sequence+last.Count == sequence && last.Offset+last.Count == offset {
+			last.Count += count
+			return
+		}
+	}
+	if len(p.Runs) == producerRuns {
+		p.Runs = append(p.Runs[:0], p.Runs[1:]...)
+	}
+	p.Runs = append(p.Runs, sequenceRun{Sequence: sequence, Offset: offset, Count: count})
+}
+
+// Forgets the producers that haven't appended for producerExpiry before now
+func expireProducers(producers map[string]*producerState, now int64) {
+	for id, p := range producers {
+		if now-p.Timestamp > int64(producerExpiry) {
+			delete(producers, id)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"os"
+	"time"
+)
+
+// Deletes segments that fall outside the retention rules every interval until the log is closed
+func (l *Log) retentionLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case now := <-ticker.C:
+			if err := l.enforceRetention(now); err != nil {
+				l.Config.Logger.Error("enforcing retention", "dir", l.Dir, "error", err)
+			}
+		}
+	}
+}
+
+// Deletes the oldest segments until the log is back within the retention rules
+//
+// We only ever delete from the front of the log so it stays contiguous, and never the active segment,
+// so the lowest offset moves forward a whole segment at a time
+// Segments that are only in the archive are the oldest, so they go first, and count towards the log's size
+func (l *Log) enforceRetention(now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var size uint64
+	for _, a := range l.remote() {
+		size += a.Size
+	}
+	for _, s := range l.segments {
+		size += s.store.size
+	}
+	for remote := l.remote(); len(remote) > 0; remote = l.remote() {
+		a := remote[0]
+		if !l.expired(a.NextOffset, size, a.ModTime, now) {
+			return nil
+		}
+		if err := l.deleteArchived(a.BaseOffset); err != nil {
+			return err
+		}
+		size -= a.Size
+	}
+	for len(l.segments) > 1 {
+		s := l.segments[0]
+		fi, err := os.Stat(s.store.Name())
+		if err != nil {
+			return err
+		}
+		if !l.expired(s.nextOffset, size, fi.ModTime(), now) {
+			return nil
+		}
+		if err := l.deleteArchived(s.baseOffset); err != nil {
+			return err
+		}
+		if err := s.Remove(); err != nil {
+			return err
+		}
+		size -= s.store.size
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// Reports whether the retention rules allow deleting the oldest segment, which ends before nextOffset and was last
+// appended to at modTime, while the log holds size bytes
+func (l *Log) expired(nextOffset, size uint64, modTime, now time.Time) bool {
+	r := l.Config.Retention
+	if r.MinOffset > 0 && nextOffset > r.MinOffset {
+		return false
+	}
+	tooBig := r.MaxBytes > 0 && size > r.MaxBytes
+	tooOld := r.MaxAge > 0 && now.Sub(modTime) > r.MaxAge
+	return tooBig || tooOld
+}
@@ -2,34 +2,61 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
-
-	"github.com/gogo/protobuf/proto"
+	"sync"
+	"time"
 
 	api "ledger/api/v1"
 )
 
 // Segment handles operations between the store and index
 type segment struct {
-	store *store
-	index *index
+	store     *store
+	index     *index
+	timeIndex *timeIndex
 
 	baseOffset uint64
 	nextOffset uint64
+	// with a sparse index, the bytes of records appended since the last index entry, and the relative offset of the
+	// last record appended
+	unindexed uint64
+	lastRel   uint64
 
+	header segmentHeader
 	config Config
+	// opened by a read-only log, so nothing is ever written to the segment's files
+	readOnly bool
+
+	// the records of the batch read last, so reading through a batch only decrypts and decompresses it once
+	batch struct {
+		sync.Mutex
+		pos     uint64
+		width   uint64
+		records [][]byte
+	}
 }
 
 func newSegment(dir string, baseOffset uint64, c Config) (*segment, error) {
+	return openSegment(dir, baseOffset, c, false)
+}
+
+// Opens the segment, which must already exist if it's opened read-only
+func openSegment(dir string, baseOffset uint64, c Config, readOnly bool) (*segment, error) {
 	s := &segment{
 		baseOffset: baseOffset,
 		config:     c,
+		readOnly:   readOnly,
+	}
+	appendFlag, indexFlag := os.O_RDWR|os.O_CREATE|os.O_APPEND, os.O_RDWR|os.O_CREATE
+	if readOnly {
+		appendFlag, indexFlag = os.O_RDONLY, os.O_RDONLY
 	}
 	storeFile, err := os.OpenFile(
 		//  filaname is {baseOffset}.store, e.g. 10.store
 		path.Join(dir, fmt.Sprintf("%d%s", baseOffset, ".store")),
-		os.O_RDWR|os.O_CREATE|os.O_APPEND,
+		appendFlag,
 		0644,
 	)
 	if err != nil {
@@ -38,64 +65,513 @@ func newSegment(dir string, baseOffset uint64, c Config) (*segment, error) {
 	if s.store, err = newStore(storeFile); err != nil {
 		return nil, err
 	}
+	if s.store.size < segmentHeaderWidth && readOnly {
+		// the header was cut off, which recovery reports, so the segment reads as empty
+		s.header = newSegmentHeader(baseOffset, c)
+	} else if s.store.size < segmentHeaderWidth {
+		// a new segment, or one whose header never made it to disk so there's nothing in it to keep
+		s.header = newSegmentHeader(baseOffset, c)
+		if err = s.store.Truncate(0); err != nil {
+			return nil, err
+		}
+		if _, err = s.store.appendRaw(s.header.encode()); err != nil {
+			return nil, err
+		}
+		if err = s.store.Flush(); err != nil {
+			return nil, err
+		}
+	} else if s.header, err = readSegmentHeader(storeFile); err != nil {
+		return nil, err
+	}
+	if s.header.BaseOffset != baseOffset {
+		return nil, fmt.Errorf("log: %s has base offset %d in its header", storeFile.Name(), s.header.BaseOffset)
+	}
 	indexFile, err := os.OpenFile(
 		path.Join(dir, fmt.Sprintf("%d%s", baseOffset, ".index")),
-		os.O_RDWR|os.O_CREATE,
+		indexFlag,
 		0644,
 	)
 	if err != nil {
 		return nil, err
 	}
-	if s.index, err = newIndex(indexFile, c); err != nil {
+	if s.index, err = newIndex(indexFile, c, readOnly); err != nil {
 		return nil, err
 	}
-	if off, _, err := s.index.Read(-1); err != nil {
-		s.nextOffset = baseOffset
-	} else {
-		s.nextOffset = baseOffset + uint64(off) + 1
+	timeIndexFile, err := os.OpenFile(
+		path.Join(dir, fmt.Sprintf("%d%s", baseOffset, ".timeindex")),
+		appendFlag,
+		0644,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if s.timeIndex, err = newTimeIndex(timeIndexFile, c, readOnly); err != nil {
+		return nil, err
+	}
+	s.nextOffset = baseOffset
+	if off, pos, err := s.index.Read(-1); err == nil {
+		// a sparse index doesn't have an entry for every record, so count the records after its last entry
+		c := cursor{off: off, pos: pos, entry: int64(s.index.size / entWidth)}
+		for {
+			f, err := s.frame(c.pos)
+			if err != nil {
+				// the end of the store, or a record that was cut off that recovery deals with
+				break
+			}
+			c = s.skipFrame(c, f)
+		}
+		s.nextOffset = baseOffset + c.off
 	}
 	return s, nil
 }
 
 // Appends the record and returns the record's offset
 func (s *segment) Append(record *api.Record) (offset uint64, err error) {
-	record.Offset = s.nextOffset
-	b, err := proto.Marshal(record)
+	return s.appendAt(s.nextOffset, record)
+}
+
+// Appends the record at the offset, which may leave a gap after the last record like compaction does
+func (s *segment) appendAt(offset uint64, record *api.Record) (uint64, error) {
+	if offset < s.nextOffset {
+		return 0, fmt.Errorf("log: can't append offset %d before the segment's next offset %d", offset, s.nextOffset)
+	}
+	record.Offset = offset
+	if record.Timestamp == 0 {
+		record.Timestamp = time.Now().UnixNano()
+	}
+	b, err := encodeRecord(record, s.config.Segment.Codec, s.config.Encryption.Keyring)
 	if err != nil {
 		return 0, err
 	}
 
-	_, pos, err := s.store.Append(b)
+	width, pos, err := s.store.Append(b)
 	if err != nil {
 		return 0, err
 	}
-	err = s.index.Write(
-		// index offsets are relative to base offset
-		uint32(s.nextOffset-s.baseOffset),
-		pos,
-	)
-	if err != nil {
+	// index offsets are relative to base offset
+	if err = s.indexFrame(offset-s.baseOffset, 1, pos, width); err != nil {
+		return 0, err
+	}
+	if err = s.timeIndex.Write(record.Timestamp, offset-s.baseOffset, width); err != nil {
 		return 0, err
 	}
 
-	cur := s.nextOffset
-	s.nextOffset += 1
-	return cur, nil
+	s.nextOffset = offset + 1
+	return offset, nil
+}
+
+// Appends the records as a single frame, compressing and encrypting them together
+// The records must already have their timestamps and consecutive offsets, the first at or after the segment's next
+// offset, which like appendAt may leave a gap after the last record
+//
+// A segment created before frames could hold a batch gets each record in a frame of its own, so it stays readable by
+// the versions that wrote it
+func (s *segment) appendBatch(records []*api.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if len(records) == 1 || s.header.Version < segmentVersion3 {
+		for _, record := range records {
+			if _, err := s.appendAt(record.Offset, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	first := records[0].Offset
+	if first < s.nextOffset {
+		return fmt.Errorf("log: can't append offset %d before the segment's next offset %d", first, s.nextOffset)
+	}
+	for i, record := range records {
+		if record.Offset != first+uint64(i) {
+			return fmt.Errorf("log: batch has offset %d after offset %d", record.Offset, first+uint64(i)-1)
+		}
+	}
+	b, err := encodeBatch(records, s.config.Segment.Codec, s.config.Encryption.Keyring)
+	if err != nil {
+		return err
+	}
+
+	width, pos, err := s.store.Append(b)
+	if err != nil {
+		return err
+	}
+	// one index entry covers the whole batch, which reads find by scanning from it
+	rel, n := first-s.baseOffset, uint64(len(records))
+	if err = s.indexFrame(rel, n, pos, width); err != nil {
+		return err
+	}
+	for i, record := range records {
+		// the batch's bytes count towards the time index's interval once, with its last record
+		var w uint64
+		if uint64(i) == n-1 {
+			w = width
+		}
+		if err = s.timeIndex.Write(record.Timestamp, rel+uint64(i), w); err != nil {
+			return err
+		}
+	}
+
+	s.nextOffset = first + n
+	return nil
+}
+
+// Appends a record that's already framed at the given relative offset, to carry it over from another segment
+func (s *segment) appendFrame(rel uint64, frame []byte) error {
+	pos, err := s.store.appendRaw(frame)
+	if err != nil {
+		return err
+	}
+	if err = s.indexFrame(rel, 1, pos, uint64(len(frame))); err != nil {
+		return err
+	}
+	if err = s.timeIndex.Write(recordTimestamp(frame[headerWidth:]), rel, uint64(len(frame))); err != nil {
+		return err
+	}
+	s.nextOffset = s.baseOffset + rel + 1
+	return nil
+}
+
+// Adds the frame at pos, holding n records from the relative offset on, to the index
+//
+// With IndexIntervalBytes set, the index is sparse: a frame only gets an entry once the frames since the last entry
+// take up that many bytes. Frames whose first record doesn't directly follow the previous frame's last, which only
+// happens in compacted segments, always get an entry, so offsets go up by one between entries and a scan from an
+// entry can count its way to any record
+func (s *segment) indexFrame(rel, n, pos, width uint64) error {
+	interval := s.config.Segment.IndexIntervalBytes
+	if interval == 0 || s.index.size == 0 || rel != s.lastRel+1 || s.unindexed >= interval {
+		if err := s.index.Write(rel, pos); err != nil {
+			return err
+		}
+		s.unindexed = 0
+	}
+	s.unindexed += width
+	s.lastRel = rel + n - 1
+	return nil
+}
+
+// Position of a record in the segment, and the number of the first index entry after its frame
+type cursor struct {
+	// relative to the segment's base offset
+	off uint64
+	// position of the frame holding the record, and the record's place in the frame, which is 0 unless the frame
+	// holds a batch
+	pos   uint64
+	i     uint64
+	entry int64
+}
+
+// What a cursor needs to know to move past a frame: the bytes it takes up in the store, header included, and the
+// number of records it holds
+type frameInfo struct {
+	width uint64
+	n     uint64
+}
+
+// Reads the header of the frame at pos, without reading or checking the rest of it
+func (s *segment) frame(pos uint64) (frameInfo, error) {
+	width, head, err := s.store.Head(pos, attrWidth+batchLenWidth)
+	if err != nil {
+		return frameInfo{}, err
+	}
+	return frameInfo{width: width, n: frameCount(head)}, nil
+}
+
+// Returns a cursor at the first record at or after the relative offset, or io.EOF if there isn't one
+//
+// This starts from the last index entry at or before the offset and scans forward through the store, which with a
+// dense index is the offset's own entry
+func (s *segment) seek(rel uint64) (cursor, error) {
+	// until the segment's compacted, the entry number of a dense index matches the relative offset
+	if off, pos, err := s.index.Read(int64(rel)); err == nil && off == rel {
+		return cursor{off: off, pos: pos, entry: int64(rel) + 1}, nil
+	}
+	// the last entry at or before the offset, or the first entry if compaction removed the records before it
+	entry := s.index.Search(rel+1) - 1
+	if entry < 0 {
+		entry = 0
+	}
+	off, pos, err := s.index.Read(entry)
+	if err != nil {
+		return cursor{pos: segmentHeaderWidth}, err
+	}
+	c := cursor{off: off, pos: pos, entry: entry + 1}
+	for c.off < rel {
+		f, err := s.frame(c.pos)
+		if err != nil {
+			return c, err
+		}
+		if rel-c.off < f.n-c.i {
+			// the offset's in this frame's batch
+			c.i += rel - c.off
+			c.off = rel
+			break
+		}
+		c = s.skipFrame(c, f)
+	}
+	if c.pos >= s.store.size {
+		return c, io.EOF
+	}
+	return c, nil
+}
+
+// Moves the cursor past the record it's at, in frame f, to the next record
+// Returns io.EOF if there isn't a next record
+func (s *segment) next(c cursor, f frameInfo) (cursor, error) {
+	c = s.advance(c, f)
+	if c.pos >= s.store.size {
+		return c, io.EOF
+	}
+	return c, nil
+}
+
+// Moves the cursor past the record it's at, in frame f, to the next record in the frame or else the next frame
+func (s *segment) advance(c cursor, f frameInfo) cursor {
+	if c.i+1 < f.n {
+		c.i++
+		c.off++
+		return c
+	}
+	return s.skipFrame(c, f)
+}
+
+// Moves the cursor past the rest of frame f to the next frame
+// The next frame's first offset follows on from f's last, unless it has an index entry saying otherwise
+func (s *segment) skipFrame(c cursor, f frameInfo) cursor {
+	if c.i < f.n {
+		c.off += f.n - c.i
+	} else {
+		c.off++
+	}
+	c.pos += f.width
+	c.i = 0
+	for {
+		off, pos, err := s.index.Read(c.entry)
+		if err != nil || pos > c.pos {
+			return c
+		}
+		if pos == c.pos {
+			c.off = off
+		}
+		c.entry++
+	}
+}
+
+// Where the segment ended at some point, so the records appended after it can be cut off again
+type segmentMark struct {
+	nextOffset uint64
+	unindexed  uint64
+	lastRel    uint64
+	storeSize  uint64
+	indexSize  uint64
+	timeIndex  timeIndex
+}
+
+func (s *segment) mark() segmentMark {
+	return segmentMark{
+		nextOffset: s.nextOffset,
+		unindexed:  s.unindexed,
+		lastRel:    s.lastRel,
+		storeSize:  s.store.size,
+		indexSize:  s.index.size,
+		timeIndex:  *s.timeIndex,
+	}
+}
+
+// Drops every record appended since the mark was taken
+func (s *segment) rollback(m segmentMark) error {
+	if err := s.store.Truncate(m.storeSize); err != nil {
+		return err
+	}
+	s.index.Truncate(m.indexSize)
+	if err := s.timeIndex.Restore(m.timeIndex); err != nil {
+		return err
+	}
+	s.nextOffset = m.nextOffset
+	s.unindexed = m.unindexed
+	s.lastRel = m.lastRel
+	s.forgetBatch()
+	return nil
 }
 
 // Find the record by offset
+//
+// If the offset was compacted away, this returns the next record after it, which compaction guarantees is in the
+// same segment
 func (s *segment) Read(offset uint64) (*api.Record, error) {
-	_, pos, err := s.index.Read(int64(offset - s.baseOffset))
+	c, err := s.seek(offset - s.baseOffset)
 	if err != nil {
 		return nil, err
 	}
-	p, err := s.store.ReadAt(pos)
+	p, _, err := s.readAt(c)
 	if err != nil {
 		return nil, err
 	}
-	record := &api.Record{}
-	err = proto.Unmarshal(p, record)
-	return record, err
+	return decodeRecordAt(p, s.baseOffset+c.off, s.config.Encryption.Keyring)
+}
+
+// Reads the content of the record at the cursor, without decoding it, along with the frame the cursor moves past with
+// next or advance
+func (s *segment) readAt(c cursor) ([]byte, frameInfo, error) {
+	records, width, err := s.readFrame(c.pos)
+	if err == nil && c.i >= uint64(len(records)) {
+		err = errCorrupt
+	}
+	if err == errCorrupt {
+		return nil, frameInfo{}, api.ErrCorruptRecord{Offset: s.baseOffset + c.off}
+	}
+	if err != nil {
+		return nil, frameInfo{}, err
+	}
+	return records[c.i], frameInfo{width: width, n: uint64(len(records))}, nil
+}
+
+// Returns the content of each record in the frame at pos, and the bytes the frame takes up in the store
+func (s *segment) readFrame(pos uint64) ([][]byte, uint64, error) {
+	s.batch.Lock()
+	if s.batch.pos == pos && s.batch.records != nil {
+		records, width := s.batch.records, s.batch.width
+		s.batch.Unlock()
+		return records, width, nil
+	}
+	s.batch.Unlock()
+	p, err := s.store.ReadAt(pos)
+	if err != nil {
+		return nil, 0, err
+	}
+	records, err := decodeFrame(p, s.config.Encryption.Keyring)
+	if err != nil {
+		return nil, 0, err
+	}
+	width := headerWidth + uint64(len(p))
+	if len(records) > 1 {
+		s.batch.Lock()
+		s.batch.pos, s.batch.width, s.batch.records = pos, width, records
+		s.batch.Unlock()
+	}
+	return records, width, nil
+}
+
+// Drops the batch readFrame kept, once the store's been cut back and its position may hold another frame
+func (s *segment) forgetBatch() {
+	s.batch.Lock()
+	s.batch.records = nil
+	s.batch.Unlock()
+}
+
+// Finds the first record with a timestamp at or after the given one
+// Returns false if every record in the segment is older
+func (s *segment) OffsetForTime(timestamp int64) (uint64, bool, error) {
+	if s.timeIndex.max.timestamp < timestamp {
+		return 0, false, nil
+	}
+	// scan forward from the last record the time index knows is older
+	c, err := s.seek(s.timeIndex.Lookup(timestamp))
+	for err == nil {
+		var p []byte
+		var f frameInfo
+		if p, f, err = s.readAt(c); err != nil {
+			return 0, false, err
+		}
+		if recordTimestamp(p) >= timestamp {
+			return s.baseOffset + c.off, true, nil
+		}
+		c, err = s.next(c, f)
+	}
+	if err == io.EOF {
+		return 0, false, nil
+	}
+	return 0, false, err
+}
+
+// Scans the store from the beginning and rebuilds the index so that both end at the last complete, valid record
+//
+// A crash can leave a partially written record at the end of the store, and an index that's still padded out to
+// MaxIndexBytes or that points past the store's end, since neither is trimmed until a clean Close
+//
+// A read-only segment is rebuilt in memory only, and its reads stop at the last complete record while the rest is
+// left on disk
+func (s *segment) recover() (Recovery, error) {
+	r := Recovery{BaseOffset: s.baseOffset}
+
+	// count the entries the index had before the crash
+	before := s.index.size / entWidth
+	if s.readOnly {
+		s.index.unshare(s.config.Segment.MaxIndexBytes)
+	}
+
+	s.index.size = 0
+	s.unindexed = 0
+	s.forgetBatch()
+	if err := s.timeIndex.Reset(); err != nil {
+		return r, err
+	}
+	// records start after the segment's header
+	pos, n := uint64(segmentHeaderWidth), uint64(0)
+	for {
+		width, err := s.store.Width(pos)
+		if err == io.EOF || err == errCorrupt {
+			// we've reached the end of the store or a record that was cut off mid-write
+			break
+		}
+		if err != nil {
+			return r, err
+		}
+		// a complete record that fails its checksum is a torn write only if it's the last one
+		// anywhere else it's damage that we keep around so reads report it instead of silently dropping the records
+		// that follow it
+		p, err := s.store.ReadAt(pos)
+		if err == errCorrupt && pos+width == s.store.size {
+			break
+		}
+		if err != nil && err != errCorrupt {
+			return r, err
+		}
+		// records carry their offset, which skips ahead past records a restored snapshot left out after compaction
+		rel, records := n, [][]byte{p}
+		if frame, err := decodeFrame(p, s.config.Encryption.Keyring); p != nil && err == nil {
+			records = frame
+			if record, err := decodeRecord(frame[0], s.config.Encryption.Keyring); err == nil && record.Offset >= s.baseOffset+n {
+				rel = record.Offset - s.baseOffset
+			}
+		}
+		count := uint64(len(records))
+		if err = s.indexFrame(rel, count, pos, width); err == io.EOF {
+			// the index is full, so whatever follows in the store can't be addressed
+			break
+		} else if err != nil {
+			return r, err
+		}
+		for i, record := range records {
+			var w uint64
+			if uint64(i) == count-1 {
+				w = width
+			}
+			if err = s.timeIndex.Write(recordTimestamp(record), rel+uint64(i), w); err != nil {
+				return r, err
+			}
+		}
+		pos += width
+		n = rel + count
+	}
+
+	if pos > s.store.size {
+		// only a read-only segment whose header was cut off, so all of it is discarded
+		pos = 0
+	}
+	r.DiscardedBytes = s.store.size - pos
+	if after := s.index.size / entWidth; before > after {
+		r.DiscardedEntries = before - after
+	}
+	if s.readOnly {
+		s.store.size = pos
+	} else if err := s.store.Truncate(pos); err != nil {
+		return r, err
+	}
+	s.nextOffset = s.baseOffset + n
+	r.NextOffset = s.nextOffset
+	return r, nil
 }
 
 // Determins whether the segment has reached its max size
@@ -104,10 +580,24 @@ func (s *segment) IsMaxed() bool {
 		s.index.size >= s.config.Segment.MaxIndexBytes
 }
 
+// Forces the store and indexes to disk
+func (s *segment) Sync() error {
+	if err := s.store.Sync(); err != nil {
+		return err
+	}
+	if err := s.index.Sync(); err != nil {
+		return err
+	}
+	return s.timeIndex.Sync()
+}
+
 func (s *segment) Close() error {
 	if err := s.index.Close(); err != nil {
 		return err
 	}
+	if err := s.timeIndex.Close(); err != nil {
+		return err
+	}
 	if err := s.store.Close(); err != nil {
 		return err
 	}
@@ -122,6 +612,9 @@ func (s *segment) Remove() error {
 	if err := os.Remove(s.index.Name()); err != nil {
 		return err
 	}
+	if err := os.Remove(s.timeIndex.Name()); err != nil {
+		return err
+	}
 	if err := os.Remove(s.store.Name()); err != nil {
 		return err
 	}
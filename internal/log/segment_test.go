@@ -57,3 +57,165 @@ func TestSegment(t *testing.T) {
 	require.Equal(t, io.EOF, err)
 	require.Nil(t, b)
 }
+
+func TestSegmentRollback(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "segment-test")
+	defer os.RemoveAll(dir)
+
+	c := Config{}
+	c.Segment.MaxStoreBytes = 1024
+	c.Segment.MaxIndexBytes = 1024
+	c.Segment.TimeIndexIntervalBytes = 1
+
+	s, err := newSegment(dir, 0, c)
+	require.NoError(t, err)
+	_, err = s.Append(&api.Record{Value: []byte("kept"), Timestamp: 1})
+	require.NoError(t, err)
+
+	mark := s.mark()
+	for i := 0; i < 3; i++ {
+		_, err = s.Append(&api.Record{Value: []byte("dropped"), Timestamp: int64(i + 2)})
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.rollback(mark))
+	require.Equal(t, uint64(1), s.nextOffset)
+	_, err = s.Read(1)
+	require.Equal(t, io.EOF, err)
+	_, ok, err := s.OffsetForTime(2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the next record takes the dropped records' place
+	off, err := s.Append(&api.Record{Value: []byte("next"), Timestamp: 5})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), off)
+	require.NoError(t, s.Close())
+
+	s, err = newSegment(dir, 0, c)
+	require.NoError(t, err)
+	record, err := s.Read(1)
+	require.NoError(t, err)
+	require.Equal(t, "next", string(record.Value))
+	require.NoError(t, s.Close())
+}
+
+func TestSegmentBatch(t *testing.T) {
+	keyring, err := NewKeyring("1", map[string][]byte{"1": make([]byte, 32)})
+	require.NoError(t, err)
+	for name, keyring := range map[string]*Keyring{"plain": nil, "encrypted": keyring} {
+		t.Run(name, func(t *testing.T) {
+			dir, _ := ioutil.TempDir("", "segment-test")
+			defer os.RemoveAll(dir)
+
+			c := Config{}
+			c.Segment.MaxStoreBytes = 1 << 16
+			c.Segment.MaxIndexBytes = 1024
+			c.Segment.TimeIndexIntervalBytes = 1
+			c.Segment.Codec = CodecGzip
+			c.Encryption.Keyring = keyring
+			value := []byte(`{"sender_id":"8c5b0a5e","receiver_id":"1f0a7d3c","amount":"100.00"}`)
+			records := func() []*api.Record {
+				var records []*api.Record
+				for i := uint64(0); i < 10; i++ {
+					records = append(records, &api.Record{Offset: i, Timestamp: int64(i + 1), Value: value})
+				}
+				return records
+			}
+
+			// the same records appended one at a time, each compressed on its own
+			single, err := newSegment(dir, 100, c)
+			require.NoError(t, err)
+			for _, record := range records() {
+				_, err = single.Append(record)
+				require.NoError(t, err)
+			}
+
+			s, err := newSegment(dir, 0, c)
+			require.NoError(t, err)
+			require.NoError(t, s.appendBatch(records()))
+			require.Equal(t, uint64(10), s.nextOffset)
+			// the whole batch is one frame with one index entry, which takes less space than compressing each record
+			require.Equal(t, uint64(entWidth), s.index.size)
+			require.Less(t, s.store.size, single.store.size)
+			require.NoError(t, single.Remove())
+
+			check := func() {
+				for off := uint64(0); off < 10; off++ {
+					record, err := s.Read(off)
+					require.NoError(t, err)
+					require.Equal(t, off, record.Offset)
+					require.Equal(t, int64(off+1), record.Timestamp)
+					require.Equal(t, value, record.Value)
+				}
+				off, ok, err := s.OffsetForTime(6)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, uint64(5), off)
+
+				c, err := s.seek(3)
+				require.NoError(t, err)
+				var offsets []uint64
+				for err == nil {
+					var f frameInfo
+					_, f, err = s.readAt(c)
+					require.NoError(t, err)
+					offsets = append(offsets, c.off)
+					c, err = s.next(c, f)
+				}
+				require.Equal(t, io.EOF, err)
+				require.Equal(t, []uint64{3, 4, 5, 6, 7, 8, 9}, offsets)
+			}
+			check()
+
+			// a batch that doesn't follow on from the segment's last record is rejected
+			require.Error(t, s.appendBatch([]*api.Record{{Offset: 12}, {Offset: 14}}))
+			require.Error(t, s.appendBatch([]*api.Record{{Offset: 5}, {Offset: 6}}))
+
+			// reopening counts the batch's records, and recovery rebuilds the index from them
+			require.NoError(t, s.Close())
+			s, err = newSegment(dir, 0, c)
+			require.NoError(t, err)
+			require.Equal(t, uint64(10), s.nextOffset)
+			r, err := s.recover()
+			require.NoError(t, err)
+			require.Equal(t, Recovery{NextOffset: 10}, r)
+			check()
+
+			// a segment created before frames could hold a batch gets a frame for each record
+			s.header.Version = segmentVersion2
+			require.NoError(t, s.appendBatch([]*api.Record{{Offset: 10}, {Offset: 11}}))
+			require.Equal(t, uint64(3*entWidth), s.index.size)
+			require.NoError(t, s.Close())
+		})
+	}
+}
+
+func BenchmarkSegmentRead(b *testing.B) {
+	for mode, interval := range map[string]uint64{"dense": 0, "sparse": 4096} {
+		b.Run(mode, func(b *testing.B) {
+			dir, _ := ioutil.TempDir("", "segment-bench")
+			defer os.RemoveAll(dir)
+
+			c := Config{}
+			c.Segment.MaxStoreBytes = 1 << 30
+			c.Segment.MaxIndexBytes = 1 << 20
+			c.Segment.IndexIntervalBytes = interval
+
+			s, err := newSegment(dir, 0, c)
+			require.NoError(b, err)
+			defer s.Close()
+			const n = 10000
+			for i := 0; i < n; i++ {
+				_, err := s.Append(&api.Record{Value: make([]byte, 128)})
+				require.NoError(b, err)
+			}
+			b.ReportMetric(float64(s.index.size), "index-bytes")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Read(uint64(i % n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package log
 import (
 	"bufio"
 	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
 	"os"
 	"sync"
 )
@@ -10,10 +13,18 @@ import (
 const (
 	// number of bytes used to store the record's length
 	lenWidth = 8
+	// number of bytes used to store the record's checksum
+	crcWidth = 4
+	// every record is framed by its length followed by its checksum
+	headerWidth = lenWidth + crcWidth
 )
 
 var (
 	enc = binary.BigEndian
+	// Castagnoli has hardware support on most platforms and better error detection than IEEE
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+	// returned when a record's bytes don't match its checksum or the record was only partially written
+	errCorrupt = errors.New("log: corrupt record")
 )
 
 func newStore(f *os.File) (*store, error) {
@@ -44,8 +55,7 @@ func (s *store) Append(p []byte) (n uint64, pos uint64, err error) {
 	defer s.mu.Unlock()
 	pos = s.size
 
-	err = binary.Write(s.buf, enc, uint64(len(p)))
-	if err != nil {
+	if _, err = s.buf.Write(frameHeader(p)); err != nil {
 		return 0, 0, err
 	}
 
@@ -54,12 +64,24 @@ func (s *store) Append(p []byte) (n uint64, pos uint64, err error) {
 		return 0, 0, err
 	}
 
-	w += lenWidth
+	w += headerWidth
 	s.size += uint64(w)
 	return uint64(w), pos, nil
 }
 
+// writes p to the end of the store as is, without framing it
+func (s *store) appendRaw(p []byte) (pos uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pos = s.size
+	n, err := s.buf.Write(p)
+	s.size += uint64(n)
+	return pos, err
+}
+
 // at the given position pos, return the record
+//
+// Returns errCorrupt if the record fails its checksum or runs past the end of the store
 func (s *store) ReadAt(pos uint64) ([]byte, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -69,20 +91,115 @@ func (s *store) ReadAt(pos uint64) ([]byte, error) {
 		return nil, err
 	}
 
-	recordLength := make([]byte, lenWidth)
-	if _, err := s.File.ReadAt(recordLength, int64(pos)); err != nil {
+	header, err := s.readHeader(pos)
+	if err != nil {
 		return nil, err
 	}
 
 	// read record's content
-	b := make([]byte, enc.Uint64(recordLength))
-	if _, err := s.File.ReadAt(b, int64(pos+lenWidth)); err != nil {
+	b := make([]byte, enc.Uint64(header[:lenWidth]))
+	if _, err := s.File.ReadAt(b, int64(pos+headerWidth)); err != nil {
 		return nil, err
 	}
+	if checksum(b) != enc.Uint32(header[lenWidth:]) {
+		return nil, errCorrupt
+	}
 
 	return b, nil
 }
 
+// Width returns the number of bytes taken up by the record framed at pos, header included, without reading or
+// checking the record's content
+func (s *store) Width(pos uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		return 0, err
+	}
+
+	header, err := s.readHeader(pos)
+	if err != nil {
+		return 0, err
+	}
+	return headerWidth + enc.Uint64(header[:lenWidth]), nil
+}
+
+// Head returns the number of bytes taken up by the record framed at pos, header included, along with up to the first n
+// bytes of its content, without checking the record's checksum
+func (s *store) Head(pos, n uint64) (uint64, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		return 0, nil, err
+	}
+
+	header, err := s.readHeader(pos)
+	if err != nil {
+		return 0, nil, err
+	}
+	size := enc.Uint64(header[:lenWidth])
+	if size < n {
+		n = size
+	}
+	b := make([]byte, n)
+	if _, err := s.File.ReadAt(b, int64(pos+headerWidth)); err != nil {
+		return 0, nil, err
+	}
+	return headerWidth + size, b, nil
+}
+
+// Reads the header of the record at pos
+// Returns errCorrupt if the header or the record it describes runs past the end of the store
+func (s *store) readHeader(pos uint64) ([]byte, error) {
+	header := make([]byte, headerWidth)
+	if _, err := s.File.ReadAt(header, int64(pos)); err != nil {
+		if err == io.EOF && pos < s.size {
+			// only part of the header made it to disk
+			return nil, errCorrupt
+		}
+		return nil, err
+	}
+
+	// a length that runs past the end of the file means the record was never fully written
+	if enc.Uint64(header[:lenWidth]) > s.size-pos-headerWidth {
+		return nil, errCorrupt
+	}
+	return header, nil
+}
+
+// Flush writes buffered records through to the file
+func (s *store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Flush()
+}
+
+// Sync writes buffered records through to the file and fsyncs it
+func (s *store) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	return s.File.Sync()
+}
+
+// Truncate cuts the store back to the given size, dropping anything written after it
+func (s *store) Truncate(size uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if err := s.File.Truncate(int64(size)); err != nil {
+		return err
+	}
+	s.size = size
+	return nil
+}
+
 // Close makes sure we persist buffered data before closing the file
 func (s *store) Close() error {
 	s.mu.Lock()
@@ -94,3 +211,16 @@ func (s *store) Close() error {
 
 	return s.File.Close()
 }
+
+// Returns the header framing the record's content: its length followed by its checksum
+func frameHeader(p []byte) []byte {
+	header := make([]byte, headerWidth)
+	enc.PutUint64(header[:lenWidth], uint64(len(p)))
+	enc.PutUint32(header[lenWidth:], checksum(p))
+	return header
+}
+
+// checksum of a record's content
+func checksum(p []byte) uint32 {
+	return crc32.Checksum(p, crcTable)
+}
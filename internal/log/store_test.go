@@ -2,6 +2,7 @@ package log
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -11,7 +12,7 @@ import (
 
 var (
 	recordValue = []byte("hello")
-	appendWidth = uint64(len(recordValue)) + lenWidth
+	appendWidth = uint64(len(recordValue)) + headerWidth
 )
 
 func TestStore(t *testing.T) {
@@ -42,6 +43,42 @@ func TestStore(t *testing.T) {
 	require.Equal(t, err, io.EOF)
 }
 
+func TestStoreCorruption(t *testing.T) {
+	f, err := ioutil.TempFile("", "store_corruption_test")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	s, err := newStore(f)
+	require.NoError(t, err)
+	testAppend(t, s, 2)
+	require.NoError(t, s.Close())
+
+	// flip a bit in the second record's content
+	f, err = os.OpenFile(f.Name(), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, int64(appendWidth+headerWidth))
+	require.NoError(t, err)
+	b[0] ^= 0x01
+	_, err = f.WriteAt(b, int64(appendWidth+headerWidth))
+	require.NoError(t, err)
+
+	s, err = newStore(f)
+	require.NoError(t, err)
+	read, err := s.ReadAt(0)
+	require.NoError(t, err)
+	require.Equal(t, recordValue, read)
+	_, err = s.ReadAt(appendWidth)
+	require.Equal(t, errCorrupt, err)
+
+	// a record whose length runs past the end of the store was only partially written
+	require.NoError(t, s.File.Truncate(int64(2*appendWidth-1)))
+	s, err = newStore(s.File)
+	require.NoError(t, err)
+	_, err = s.ReadAt(appendWidth)
+	require.Equal(t, errCorrupt, err)
+}
+
 func testAppend(t *testing.T, s *store, numAppends int) {
 	t.Helper()
 	for i := 1; i <= numAppends; i++ {
@@ -0,0 +1,432 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// records which segments have been uploaded to the archive and which of those are still on local disk
+	manifestFile = "manifest.json"
+	// where segments fetched back from the archive are kept for later reads
+	archiveCacheDir = "archive-cache"
+)
+
+// the files that make up a segment, uploaded in this order so an archived store always has its indexes
+var segmentExts = []string{".index", ".timeindex", ".store"}
+
+// The segments uploaded to the archive, oldest first
+type manifest struct {
+	Segments []archivedSegment `json:"segments"`
+}
+
+// A segment uploaded to the archive, with what the log needs to know about it once it's no longer on local disk
+type archivedSegment struct {
+	BaseOffset uint64 `json:"base_offset"`
+	NextOffset uint64 `json:"next_offset"`
+	// bytes in the segment's store
+	Size uint64 `json:"size"`
+	// highest record timestamp in the segment, in unix nanoseconds
+	MaxTimestamp int64 `json:"max_timestamp"`
+	// when the segment was last appended to, so retention still ages it from there
+	ModTime time.Time `json:"mod_time"`
+	// false once the local copy has been deleted
+	Local bool `json:"local"`
+}
+
+// Uploads closed segments to the archive every interval until the log is closed
+func (l *Log) tieringLoop(interval time.Duration) {
+	defer l.background.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Offload(); err != nil {
+				l.Config.Logger.Error("offloading", "dir", l.Dir, "error", err)
+			}
+		}
+	}
+}
+
+// Offload uploads the closed segments that aren't in the archive yet, then deletes the local copies of the oldest
+// uploaded segments until the local segments are back within Tiering.LocalBytes
+//
+// Segments are only ever deleted from local disk oldest first, so the segments that are only in the archive all come
+// before the local ones. They stay readable through Read, the iterator and OffsetForTime, which fetch them back from
+// the archive and cache them. Does nothing if the log has no archive.
+func (l *Log) Offload() error {
+	archive := l.Config.Tiering.Archive
+	if archive == nil {
+		return nil
+	}
+	if l.readOnly {
+		return errReadOnly
+	}
+	// compaction rewrites closed segments, so it mustn't swap one in while we're uploading it
+	l.compactMu.Lock()
+	defer l.compactMu.Unlock()
+
+	l.mu.RLock()
+	var pending []*segment
+	for _, s := range l.segments[:len(l.segments)-1] {
+		if l.archivedIndex(s.baseOffset) == -1 {
+			pending = append(pending, s)
+		}
+	}
+	l.mu.RUnlock()
+
+	for _, s := range pending {
+		a, err := l.upload(s)
+		if err != nil {
+			return err
+		}
+		l.mu.Lock()
+		// retention may have deleted the segment while we were uploading it
+		if l.segmentIndex(s) != -1 {
+			l.manifest.Segments = append(l.manifest.Segments, a)
+			sort.Slice(l.manifest.Segments, func(i, j int) bool {
+				return l.manifest.Segments[i].BaseOffset < l.manifest.Segments[j].BaseOffset
+			})
+			err = l.saveManifest()
+		}
+		l.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.evict()
+}
+
+// Copies the segment's files to the archive
+func (l *Log) upload(s *segment) (archivedSegment, error) {
+	// open the files while holding the lock so retention can't delete them first, we can keep reading them after
+	// that even if it does
+	l.mu.RLock()
+	a := archivedSegment{
+		BaseOffset:   s.baseOffset,
+		NextOffset:   s.nextOffset,
+		Size:         s.store.size,
+		MaxTimestamp: s.timeIndex.max.timestamp,
+		Local:        true,
+	}
+	// the index file is padded out to MaxIndexBytes, so only its entries are uploaded
+	sizes := []int64{int64(s.index.size), -1, int64(s.store.size)}
+	var files []*os.File
+	var err error
+	for _, name := range []string{s.index.Name(), s.timeIndex.Name(), s.store.Name()} {
+		var f *os.File
+		if f, err = os.Open(name); err != nil {
+			break
+		}
+		files = append(files, f)
+	}
+	if err == nil {
+		var fi os.FileInfo
+		if fi, err = os.Stat(s.store.Name()); err == nil {
+			a.ModTime = fi.ModTime()
+		}
+	}
+	l.mu.RUnlock()
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	if err != nil {
+		return a, err
+	}
+
+	for i, f := range files {
+		var r io.Reader = f
+		if sizes[i] >= 0 {
+			r = io.LimitReader(f, sizes[i])
+		}
+		if err = l.Config.Tiering.Archive.Put(filepath.Base(f.Name()), r); err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// Deletes the local copies of the oldest uploaded segments while the local segments hold more than
+// Tiering.LocalBytes, stopping at the first segment that hasn't been uploaded yet
+// Must be called with the log's lock held
+func (l *Log) evict() error {
+	max := l.Config.Tiering.LocalBytes
+	if max == 0 {
+		return nil
+	}
+	var size uint64
+	for _, s := range l.segments {
+		size += s.store.size
+	}
+	for size > max && len(l.segments) > 1 {
+		s := l.segments[0]
+		i := l.archivedIndex(s.baseOffset)
+		if i == -1 {
+			return nil
+		}
+		// the manifest is saved first, so if we crash before the files are deleted, setup finishes deleting them
+		l.manifest.Segments[i].Local = false
+		if err := l.saveManifest(); err != nil {
+			return err
+		}
+		if err := s.Remove(); err != nil {
+			return err
+		}
+		size -= s.store.size
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// Returns the segments that are only in the archive, oldest first
+// Must be called with the log's lock held
+func (l *Log) remote() []archivedSegment {
+	n := sort.Search(len(l.manifest.Segments), func(i int) bool {
+		return l.manifest.Segments[i].Local
+	})
+	return l.manifest.Segments[:n]
+}
+
+// Returns the position of the segment with the base offset in the manifest, or -1 if it hasn't been uploaded
+// Must be called with the log's lock held
+func (l *Log) archivedIndex(baseOffset uint64) int {
+	for i, a := range l.manifest.Segments {
+		if a.BaseOffset == baseOffset {
+			return i
+		}
+	}
+	return -1
+}
+
+// Drops the segment with the base offset from the manifest, without deleting it from the archive, so it's uploaded
+// again on the next pass
+// Must be called with the log's lock held
+func (l *Log) forgetArchived(baseOffset uint64) error {
+	i := l.archivedIndex(baseOffset)
+	if i == -1 {
+		return nil
+	}
+	l.manifest.Segments = append(l.manifest.Segments[:i], l.manifest.Segments[i+1:]...)
+	return l.saveManifest()
+}
+
+// Deletes the segment with the base offset from the archive and its cache, if it was uploaded
+// Must be called with the log's lock held
+func (l *Log) deleteArchived(baseOffset uint64) error {
+	if l.archivedIndex(baseOffset) == -1 {
+		return nil
+	}
+	// forgetting it first means a crash partway through only leaves unused files in the archive
+	if err := l.forgetArchived(baseOffset); err != nil {
+		return err
+	}
+	l.cacheMu.Lock()
+	err := l.uncache(baseOffset)
+	l.cacheMu.Unlock()
+	if err != nil || l.Config.Tiering.Archive == nil {
+		return err
+	}
+	for _, ext := range segmentExts {
+		if err := l.Config.Tiering.Archive.Delete(fmt.Sprintf("%d%s", baseOffset, ext)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads the manifest, and finishes deleting the local copies of segments that were evicted before a crash
+func (l *Log) loadManifest() error {
+	if err := l.readManifest(); err != nil {
+		return err
+	}
+	for _, a := range l.remote() {
+		for _, ext := range segmentExts {
+			name := filepath.Join(l.Dir, fmt.Sprintf("%d%s", a.BaseOffset, ext))
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the manifest, or leaves it empty if nothing's been uploaded
+func (l *Log) readManifest() error {
+	l.manifest = manifest{}
+	b, err := ioutil.ReadFile(filepath.Join(l.Dir, manifestFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &l.manifest); err != nil {
+		return fmt.Errorf("log: reading %s: %v", manifestFile, err)
+	}
+	if len(l.remote()) > 0 && l.Config.Tiering.Archive == nil {
+		return fmt.Errorf("log: %d segments are only in the archive, but the log has no archive", len(l.remote()))
+	}
+	return nil
+}
+
+// Writes the manifest to a temporary file and renames it into place, so it's never left half written
+// Must be called with the log's lock held
+func (l *Log) saveManifest() error {
+	b, err := json.Marshal(l.manifest)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(l.Dir, manifestFile+".tmp")
+	if err = ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(l.Dir, manifestFile))
+}
+
+// Reads the payload of the first record at or after the offset from the segments that are only in the archive
+// Starts from the oldest archived record if the offset has been deleted, and returns false if the offset isn't older
+// than every local segment
+func (l *Log) readArchived(offset uint64) ([]byte, uint64, bool, error) {
+	l.mu.RLock()
+	remote := l.remote()
+	i := sort.Search(len(remote), func(i int) bool {
+		return remote[i].NextOffset > offset
+	})
+	if i == len(remote) {
+		l.mu.RUnlock()
+		return nil, 0, false, nil
+	}
+	a := remote[i]
+	l.mu.RUnlock()
+	if offset < a.BaseOffset {
+		offset = a.BaseOffset
+	}
+
+	// fetching the segment can be slow, so we hold the cache's lock rather than the log's
+	l.cacheMu.Lock()
+	defer l.cacheMu.Unlock()
+	s, err := l.fetch(a)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	// compaction keeps each segment's last record, so there's always a record at or after the offset
+	c, err := s.seek(offset - s.baseOffset)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	p, _, err := s.readAt(c)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return p, s.baseOffset + c.off, true, nil
+}
+
+// Finds the first record with a timestamp at or after the given one in the segments that are only in the archive
+// Returns false if every archived record is older
+func (l *Log) archivedOffsetForTime(timestamp int64) (uint64, bool, error) {
+	l.mu.RLock()
+	var a *archivedSegment
+	for _, r := range l.remote() {
+		if r.MaxTimestamp >= timestamp {
+			a = &r
+			break
+		}
+	}
+	l.mu.RUnlock()
+	if a == nil {
+		return 0, false, nil
+	}
+
+	l.cacheMu.Lock()
+	defer l.cacheMu.Unlock()
+	s, err := l.fetch(*a)
+	if err != nil {
+		return 0, false, err
+	}
+	return s.OffsetForTime(timestamp)
+}
+
+// Returns the archived segment, downloading it into the cache if it isn't there already, and evicts the least
+// recently read segments while the cache holds more than Tiering.CacheBytes
+// Must be called with the cache's lock held
+func (l *Log) fetch(a archivedSegment) (*segment, error) {
+	for i, s := range l.cache {
+		if s.baseOffset == a.BaseOffset {
+			// most recently read segments go last
+			l.cache = append(append(l.cache[:i:i], l.cache[i+1:]...), s)
+			return s, nil
+		}
+	}
+
+	dir := l.cacheDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	for _, ext := range segmentExts {
+		if err := l.download(fmt.Sprintf("%d%s", a.BaseOffset, ext), dir); err != nil {
+			return nil, err
+		}
+	}
+	s, err := newSegment(dir, a.BaseOffset, l.Config)
+	if err != nil {
+		return nil, err
+	}
+	s.nextOffset = a.NextOffset
+	l.cache = append(l.cache, s)
+
+	var size uint64
+	for _, s := range l.cache {
+		size += s.store.size
+	}
+	for size > l.Config.Tiering.CacheBytes && len(l.cache) > 1 {
+		size -= l.cache[0].store.size
+		if err = l.uncache(l.cache[0].baseOffset); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Copies the file from the archive into the directory
+func (l *Log) download(name, dir string) error {
+	r, err := l.Config.Tiering.Archive.Get(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Deletes the segment with the base offset from the cache, if it's there
+// Must be called with the cache's lock held
+func (l *Log) uncache(baseOffset uint64) error {
+	for i, s := range l.cache {
+		if s.baseOffset == baseOffset {
+			l.cache = append(l.cache[:i], l.cache[i+1:]...)
+			return s.Remove()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+var (
+	// timestamps are stored as int64 unix nanoseconds
+	tsWidth uint64 = 8
+	// timeEntWidth is used to jump to the position of a time index entry
+	timeEntWidth = tsWidth + offWidth
+)
+
+// Sparse index from record timestamps to offsets, kept next to a segment's offset index
+//
+// Timestamps come from producers, so they aren't necessarily in offset order. Each entry holds the highest timestamp
+// appended to the segment so far and the relative offset of the record that carried it, which keeps the entries
+// sorted by timestamp and means every record up to an entry's offset is no newer than the entry
+// A new entry is only added once TimeIndexIntervalBytes have been appended since the last one
+type timeIndex struct {
+	file *os.File
+	// the whole file, loaded into memory since it's sparse
+	entries []timeEntry
+	// highest timestamp appended to the segment so far, which may not have an entry yet
+	max timeEntry
+	// bytes appended since the last entry
+	bytes    uint64
+	interval uint64
+	// entries added to a read-only index are only kept in memory
+	readOnly bool
+}
+
+type timeEntry struct {
+	timestamp int64
+	// relative to the segment's base offset
+	off uint64
+}
+
+// creates a time index from the given file
+func newTimeIndex(f *os.File, c Config, readOnly bool) (*timeIndex, error) {
+	t := &timeIndex{
+		file:     f,
+		interval: c.Segment.TimeIndexIntervalBytes,
+		readOnly: readOnly,
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	for pos := uint64(0); pos+timeEntWidth <= uint64(len(b)); pos += timeEntWidth {
+		t.entries = append(t.entries, timeEntry{
+			timestamp: int64(enc.Uint64(b[pos : pos+tsWidth])),
+			off:       enc.Uint64(b[pos+tsWidth : pos+timeEntWidth]),
+		})
+	}
+	// drop an entry that was only partially written
+	if size := int64(len(t.entries)) * int64(timeEntWidth); size < int64(len(b)) && !readOnly {
+		if err = f.Truncate(size); err != nil {
+			return nil, err
+		}
+	}
+	if n := len(t.entries); n > 0 {
+		t.max = t.entries[n-1]
+	}
+	return t, nil
+}
+
+// Records that the record at the relative offset, taking up width bytes in the store, has the given timestamp
+func (t *timeIndex) Write(timestamp int64, off uint64, width uint64) error {
+	t.bytes += width
+	if timestamp <= t.max.timestamp {
+		return nil
+	}
+	t.max = timeEntry{timestamp: timestamp, off: off}
+	if t.bytes < t.interval {
+		return nil
+	}
+	return t.add(t.max)
+}
+
+// Adds an entry for the highest timestamp if it doesn't have one yet, so the index knows how new the segment's
+// records get once we stop appending to it
+func (t *timeIndex) Flush() error {
+	if n := len(t.entries); t.max.timestamp == 0 || (n > 0 && t.entries[n-1] == t.max) {
+		return nil
+	}
+	return t.add(t.max)
+}
+
+func (t *timeIndex) add(e timeEntry) error {
+	b := make([]byte, timeEntWidth)
+	enc.PutUint64(b[:tsWidth], uint64(e.timestamp))
+	enc.PutUint64(b[tsWidth:], e.off)
+	if !t.readOnly {
+		if _, err := t.file.Write(b); err != nil {
+			return err
+		}
+	}
+	t.entries = append(t.entries, e)
+	t.bytes = 0
+	return nil
+}
+
+// Lookup returns the relative offset to start scanning from for the first record at or after the timestamp
+//
+// Every record before the returned offset is older than the timestamp
+func (t *timeIndex) Lookup(timestamp int64) uint64 {
+	// the first entry that isn't older than the timestamp
+	i := sort.Search(len(t.entries), func(j int) bool {
+		return t.entries[j].timestamp >= timestamp
+	})
+	if i == 0 {
+		return 0
+	}
+	return t.entries[i-1].off + 1
+}
+
+// Restores the index to the copy of it taken earlier, dropping the entries added since
+func (t *timeIndex) Restore(prev timeIndex) error {
+	if err := t.file.Truncate(int64(len(prev.entries)) * int64(timeEntWidth)); err != nil {
+		return err
+	}
+	t.entries, t.max, t.bytes = prev.entries, prev.max, prev.bytes
+	return nil
+}
+
+// Reset drops every entry so the index can be rebuilt from the store
+func (t *timeIndex) Reset() error {
+	if !t.readOnly {
+		if err := t.file.Truncate(0); err != nil {
+			return err
+		}
+	}
+	t.entries = nil
+	t.max = timeEntry{}
+	t.bytes = 0
+	return nil
+}
+
+func (t *timeIndex) Sync() error {
+	return t.file.Sync()
+}
+
+func (t *timeIndex) Close() error {
+	if t.readOnly {
+		return t.file.Close()
+	}
+	if err := t.Flush(); err != nil {
+		return err
+	}
+	if err := t.file.Sync(); err != nil {
+		return err
+	}
+	return t.file.Close()
+}
+
+func (t *timeIndex) Name() string {
+	return t.file.Name()
+}
@@ -0,0 +1,371 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	api "ledger/api/v1"
+)
+
+// topic requests that don't name one go to
+const DefaultTopic = "default"
+
+// topic names double as directory names, so they're kept to characters that are safe in paths
+var topicName = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// Topics is a set of named logs, each with its own segments in a directory named after the topic
+//
+// The default topic always exists, and requests that don't name a topic go to it
+type Topics struct {
+	mu     sync.RWMutex
+	Dir    string
+	Config Config
+	logs   map[string]*Log
+	// each topic's producers by ID, kept alongside the records so the FSM snapshots and restores them together
+	producersMu sync.Mutex
+	producers   map[string]map[string]*producerState
+	// each topic's committed offsets by consumer group
+	offsetsMu sync.Mutex
+	offsets   map[string]map[string]uint64
+}
+
+// Opens the topics in the directory, creating the default topic if it doesn't exist yet
+//
+// A directory holding a single log from before there were topics becomes the default topic
+func NewTopics(dir string, c Config) (*Topics, error) {
+	t := &Topics{
+		Dir:       dir,
+		Config:    withDefaults(c),
+		logs:      make(map[string]*Log),
+		producers: make(map[string]map[string]*producerState),
+		offsets:   make(map[string]map[string]uint64),
+	}
+	if err := t.setup(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Topics) setup() error {
+	if err := t.migrate(); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(t.Dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !file.IsDir() || !topicName.MatchString(file.Name()) {
+			continue
+		}
+		if err = t.open(file.Name()); err != nil {
+			return err
+		}
+	}
+	if _, ok := t.logs[DefaultTopic]; !ok {
+		return t.open(DefaultTopic)
+	}
+	return nil
+}
+
+// Moves the segments of a log written before there were topics into the default topic's directory
+func (t *Topics) migrate() error {
+	stores, err := filepath.Glob(filepath.Join(t.Dir, "*.store"))
+	if err != nil || len(stores) == 0 {
+		return err
+	}
+	dir := filepath.Join(t.Dir, DefaultTopic)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(t.Dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		// the log's own working directories move along with its segments
+		switch name := file.Name(); {
+		case !file.IsDir(),
+			name == compactingDir,
+			name == swappingDir,
+			name == upgradingDir,
+			name == archiveCacheDir:
+			if err = os.Rename(filepath.Join(t.Dir, name), filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Opens the topic's log, creating its directory if needed
+// Must be called with the lock held
+func (t *Topics) open(name string) error {
+	dir := filepath.Join(t.Dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	c := t.Config
+	if c.Tiering.Archive != nil && name != DefaultTopic {
+		// the default topic keeps the archive's root, where its segments were archived before there were topics
+		c.Tiering.Archive = prefixArchive{c.Tiering.Archive, name + "/"}
+	}
+	if tc, ok := c.Topics[name]; ok {
+		c.Retention.MaxBytes = tc.RetentionMaxBytes
+		c.Retention.MaxAge = tc.RetentionMaxAge
+		c.Compaction.Enabled = tc.Compaction
+		c.Tiering.LocalBytes = tc.ArchiveLocalBytes
+	}
+	l, err := NewLog(dir, c)
+	if err != nil {
+		return err
+	}
+	l.reportRecovery()
+	t.logs[name] = l
+	return nil
+}
+
+// Returns the topic's log, or the default topic's if the name is empty
+func (t *Topics) Log(name string) (*Log, error) {
+	if name == "" {
+		name = DefaultTopic
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	l, ok := t.logs[name]
+	if !ok {
+		return nil, api.ErrTopicNotFound{Topic: name}
+	}
+	return l, nil
+}
+
+func (t *Topics) CreateTopic(name string) error {
+	if !topicName.MatchString(name) || name == "." || name == ".." {
+		return fmt.Errorf("invalid topic name %q, use up to 249 letters, digits, '.', '_' and '-'", name)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.logs[name]; ok {
+		return api.ErrTopicExists{Topic: name}
+	}
+	return t.open(name)
+}
+
+// Deletes the topic and every record in it
+// The default topic can't be deleted
+func (t *Topics) DeleteTopic(name string) error {
+	if name == DefaultTopic {
+		return fmt.Errorf("the %s topic can't be deleted", DefaultTopic)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.logs[name]
+	if !ok {
+		return api.ErrTopicNotFound{Topic: name}
+	}
+	delete(t.logs, name)
+	t.producersMu.Lock()
+	delete(t.producers, name)
+	t.producersMu.Unlock()
+	t.offsetsMu.Lock()
+	delete(t.offsets, name)
+	t.offsetsMu.Unlock()
+	return l.Remove()
+}
+
+// Returns the names of the topics in order
+func (t *Topics) ListTopics() ([]string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.logs))
+	for name := range t.logs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (t *Topics) Append(topic string, record *api.Record) (uint64, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return 0, err
+	}
+	return l.Append(record)
+}
+
+func (t *Topics) AppendBatch(topic string, records []*api.Record) ([]uint64, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return nil, err
+	}
+	return l.AppendBatch(records)
+}
+
+// Appends the records the producer hasn't appended yet, as a unit, and returns every record's offset, where a
+// duplicate gets the offset it was first appended at
+func (t *Topics) AppendFrom(topic string, producer Producer, records []*api.Record) ([]uint64, error) {
+	offsets, wait, err := t.appendFrom(topic, producer, records, time.Now().UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	return offsets, wait()
+}
+
+// Appends the records the producer hasn't appended yet and returns every record's offset along with a function that
+// blocks until they're durable
+// now is when the records were appended, which producers expire by
+func (t *Topics) appendFrom(topic string, producer Producer, records []*api.Record, now int64) (
+	[]uint64,
+	func() error,
+	error,
+) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return nil, nil, err
+	}
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	t.producersMu.Lock()
+	defer t.producersMu.Unlock()
+	producers, ok := t.producers[topic]
+	if !ok {
+		producers = make(map[string]*producerState)
+		t.producers[topic] = producers
+	}
+	// a producer we haven't seen can start from any sequence number
+	state, ok := producers[producer.ID]
+	var offsets []uint64
+	if ok {
+		if offsets, err = state.duplicates(producer.ID, producer.Sequence, len(records)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(offsets) == len(records) {
+		return offsets, func() error { return nil }, nil
+	}
+	skip := uint64(len(offsets))
+	appended, wait, err := l.appendBatch(records[skip:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		expireProducers(producers, now)
+		state = &producerState{}
+		producers[producer.ID] = state
+	}
+	state.appended(producer.Sequence+skip, appended[0], uint64(len(appended)), now)
+	return append(offsets, appended...), wait, nil
+}
+
+// Returns the topic's producers encoded for a snapshot, or nil if it has none
+func (t *Topics) snapshotProducers(topic string) ([]byte, error) {
+	t.producersMu.Lock()
+	defer t.producersMu.Unlock()
+	producers := t.producers[topic]
+	if len(producers) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(producers)
+}
+
+// Replaces the topic's producers with the ones a snapshot holds
+func (t *Topics) restoreProducers(topic string, b []byte) error {
+	producers := make(map[string]*producerState)
+	if err := json.Unmarshal(b, &producers); err != nil {
+		return err
+	}
+	t.producersMu.Lock()
+	defer t.producersMu.Unlock()
+	t.producers[topic] = producers
+	return nil
+}
+
+func (t *Topics) Read(topic string, offset uint64) (*api.Record, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return nil, err
+	}
+	return l.Read(offset)
+}
+
+func (t *Topics) OffsetForTime(topic string, tm time.Time) (uint64, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return 0, err
+	}
+	return l.OffsetForTime(tm)
+}
+
+func (t *Topics) Iterator(topic string, from, to, maxBytes uint64) (*Iterator, error) {
+	l, err := t.Log(topic)
+	if err != nil {
+		return nil, err
+	}
+	return l.Iterator(from, to, maxBytes), nil
+}
+
+// Reads are always up to date, since there's only the one copy
+func (t *Topics) WaitForConsistency(c api.Consistency) error {
+	return nil
+}
+
+func (t *Topics) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, l := range t.logs {
+		if err := l.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes every topic, leaving an empty default topic
+// Their segments are only deleted from local disk, not from the archive, like Log.Reset
+func (t *Topics) Reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name, l := range t.logs {
+		if err := l.Close(); err != nil {
+			return err
+		}
+		if err := l.discard(); err != nil {
+			return err
+		}
+		delete(t.logs, name)
+	}
+	t.producersMu.Lock()
+	t.producers = make(map[string]map[string]*producerState)
+	t.producersMu.Unlock()
+	t.offsetsMu.Lock()
+	t.offsets = make(map[string]map[string]uint64)
+	t.offsetsMu.Unlock()
+	return t.open(DefaultTopic)
+}
+
+// Stores a topic's segments under a prefix in an archive shared by every topic
+type prefixArchive struct {
+	SegmentArchive
+	prefix string
+}
+
+func (a prefixArchive) Put(name string, r io.Reader) error {
+	return a.SegmentArchive.Put(a.prefix+name, r)
+}
+
+func (a prefixArchive) Get(name string) (io.ReadCloser, error) {
+	return a.SegmentArchive.Get(a.prefix + name)
+}
+
+func (a prefixArchive) Delete(name string) error {
+	return a.SegmentArchive.Delete(a.prefix + name)
+}
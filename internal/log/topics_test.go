@@ -0,0 +1,336 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	api "ledger/api/v1"
+)
+
+func TestTopicsSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	topics, err := NewTopics(dir, Config{})
+	require.NoError(t, err)
+	defer topics.Close()
+	require.NoError(t, topics.CreateTopic("payments"))
+	for _, topic := range []string{DefaultTopic, "payments"} {
+		for i := 0; i < 3; i++ {
+			_, err = topics.Append(topic, &api.Record{Value: []byte(topic)})
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, topics.CommitOffset("payments", "projection", 2))
+
+	sink := &testSink{}
+	snap, err := (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+
+	restoreDir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(restoreDir)
+	restored, err := NewTopics(restoreDir, Config{})
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, restored.CreateTopic("orders"))
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+
+	names, err := restored.ListTopics()
+	require.NoError(t, err)
+	require.Equal(t, []string{DefaultTopic, "payments"}, names)
+	for _, topic := range names {
+		for off := uint64(0); off < 3; off++ {
+			record, err := restored.Read(topic, off)
+			require.NoError(t, err)
+			require.Equal(t, []byte(topic), record.Value)
+		}
+	}
+	offset, err := restored.CommittedOffset("payments", "projection")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), offset)
+	_, err = restored.CommittedOffset(DefaultTopic, "projection")
+	require.Equal(t, api.ErrOffsetNotCommitted{Group: "projection", Topic: DefaultTopic}, err)
+
+	// snapshots taken before there were topics restore into the default topic
+	var legacy bytes.Buffer
+	legacy.WriteByte(0)
+	p, err := encodeRecord(&api.Record{Value: []byte("legacy")}, CodecNone, nil)
+	require.NoError(t, err)
+	legacy.Write(frameHeader(p))
+	legacy.Write(p)
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&legacy)))
+	names, err = restored.ListTopics()
+	require.NoError(t, err)
+	require.Equal(t, []string{DefaultTopic}, names)
+	record, err := restored.Read(DefaultTopic, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte("legacy"), record.Value)
+}
+
+func TestTopicsAppendFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-append-from-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	topics, err := NewTopics(dir, Config{})
+	require.NoError(t, err)
+	defer topics.Close()
+	records := func(n int) []*api.Record {
+		var records []*api.Record
+		for i := 0; i < n; i++ {
+			records = append(records, &api.Record{Value: []byte("payment"), Timestamp: 1})
+		}
+		return records
+	}
+
+	// a new producer starts from any sequence number
+	offsets, err := topics.AppendFrom("", Producer{ID: "a", Sequence: 10}, records(3))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{0, 1, 2}, offsets)
+	_, err = topics.Append("", &api.Record{Value: []byte("other")})
+	require.NoError(t, err)
+
+	// a retry gets the original offsets, and only the records after it are appended
+	offsets, err = topics.AppendFrom("", Producer{ID: "a", Sequence: 11}, records(3))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 4}, offsets)
+	offsets, err = topics.AppendFrom("", Producer{ID: "a", Sequence: 10}, records(1))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{0}, offsets)
+
+	// skipping a sequence number loses a record, so it's refused
+	_, err = topics.AppendFrom("", Producer{ID: "a", Sequence: 15}, records(1))
+	require.Equal(t, api.ErrOutOfOrderSequence{ProducerId: "a", Sequence: 15, Expected: 14}, err)
+
+	// the producers survive a snapshot
+	sink := &testSink{}
+	snap, err := (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+	restoreDir, err := ioutil.TempDir("", "topics-append-from-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(restoreDir)
+	restored, err := NewTopics(restoreDir, Config{})
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+	offsets, err = restored.AppendFrom("", Producer{ID: "a", Sequence: 13}, records(2))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 5}, offsets)
+
+	// offsets are only remembered for the producer's last runs of records
+	for i := uint64(0); i < producerRuns; i++ {
+		_, err = restored.Append("", &api.Record{Value: []byte("other")})
+		require.NoError(t, err)
+		_, err = restored.AppendFrom("", Producer{ID: "a", Sequence: 15 + i}, records(1))
+		require.NoError(t, err)
+	}
+	_, err = restored.AppendFrom("", Producer{ID: "a", Sequence: 10}, records(1))
+	require.Equal(t, api.ErrDuplicateSequence{ProducerId: "a", Sequence: 10}, err)
+}
+
+func TestTopicsSnapshotKeepsOffsets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// three records to a segment
+	c := Config{}
+	c.Segment.MaxIndexBytes = 3 * entWidth
+	topics, err := NewTopics(dir, c)
+	require.NoError(t, err)
+	defer topics.Close()
+	for i := 0; i < 10; i++ {
+		_, err = topics.Append("", &api.Record{Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+	log, err := topics.Log("")
+	require.NoError(t, err)
+	require.NoError(t, log.Truncate(2))
+
+	sink := &testSink{}
+	snap, err := (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+	restoreDir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(restoreDir)
+	restored, err := NewTopics(restoreDir, c)
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+
+	// the follower serves the same offsets as the leader it restored from
+	restoredLog, err := restored.Log("")
+	require.NoError(t, err)
+	lowest, err := restoredLog.LowestOffset()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), lowest)
+	for off := uint64(3); off < 10; off++ {
+		record, err := restored.Read("", off)
+		require.NoError(t, err)
+		require.Equal(t, off, record.Offset)
+		require.Equal(t, fmt.Sprintf("record-%d", off), string(record.Value))
+	}
+	_, err = restored.Read("", 2)
+	require.Equal(t, api.ErrOffsetOutOfRange{Offset: 2}, err)
+	off, err := restored.Append("", &api.Record{Value: []byte("record-10")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), off)
+}
+
+func TestTopicsSnapshotKeepsCompactedOffsets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := Config{}
+	c.Segment.MaxIndexBytes = 3 * entWidth
+	topics, err := NewTopics(dir, c)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = topics.Append("", &api.Record{Key: []byte{byte(i % 2)}, Value: []byte(fmt.Sprintf("record-%d", i))})
+		require.NoError(t, err)
+	}
+	log, err := topics.Log("")
+	require.NoError(t, err)
+	require.NoError(t, log.Compact())
+	// the record read at each offset, which for a compacted offset is the next record after it
+	records := func(topics *Topics) map[uint64]string {
+		log, err := topics.Log("")
+		require.NoError(t, err)
+		highest, err := log.HighestOffset()
+		require.NoError(t, err)
+		records := make(map[uint64]string)
+		for off := uint64(0); off <= highest; off++ {
+			record, err := topics.Read("", off)
+			require.NoError(t, err)
+			records[off] = string(record.Value)
+		}
+		return records
+	}
+	want := records(topics)
+	require.Equal(t, "record-2", want[0])
+
+	sink := &testSink{}
+	snap, err := (&fsm{topics: topics}).Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, snap.Persist(sink))
+	require.NoError(t, topics.Close())
+
+	// the restored log keeps every record in its active segment, gaps and all
+	restoreDir, err := ioutil.TempDir("", "topics-snapshot-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(restoreDir)
+	restored, err := NewTopics(restoreDir, Config{})
+	require.NoError(t, err)
+	require.NoError(t, (&fsm{topics: restored}).Restore(ioutil.NopCloser(&sink.Buffer)))
+	require.Equal(t, want, records(restored))
+	off, err := restored.Append("", &api.Record{Value: []byte("record-10")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), off)
+	want[10] = "record-10"
+	require.NoError(t, restored.Close())
+
+	// and so does recovering the active segment when the log's opened again
+	restored, err = NewTopics(restoreDir, Config{})
+	require.NoError(t, err)
+	defer restored.Close()
+	require.Equal(t, want, records(restored))
+}
+
+func TestTopicsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-config-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := Config{}
+	c.Retention.MaxAge = time.Hour
+	c.Topics = map[string]TopicConfig{"balances": {Compaction: true}}
+	topics, err := NewTopics(dir, c)
+	require.NoError(t, err)
+	defer topics.Close()
+	require.NoError(t, topics.CreateTopic("balances"))
+	require.NoError(t, topics.CreateTopic("journal"))
+
+	// a topic with settings of its own gets only those, the rest keep the log-wide ones
+	balances, err := topics.Log("balances")
+	require.NoError(t, err)
+	require.True(t, balances.Config.Compaction.Enabled)
+	require.Equal(t, time.Duration(0), balances.Config.Retention.MaxAge)
+	journal, err := topics.Log("journal")
+	require.NoError(t, err)
+	require.False(t, journal.Config.Compaction.Enabled)
+	require.Equal(t, time.Hour, journal.Config.Retention.MaxAge)
+}
+
+func TestTopicsLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-logger-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	c := Config{Logger: hclog.New(&hclog.LoggerOptions{Output: &out})}
+	topics, err := NewTopics(dir, c)
+	require.NoError(t, err)
+	_, err = topics.Append(DefaultTopic, &api.Record{Value: []byte("record")})
+	require.NoError(t, err)
+	require.NoError(t, topics.Close())
+
+	// a record cut off by a crash is reported through the config's logger when the topic's opened again
+	f, err := os.OpenFile(filepath.Join(dir, DefaultTopic, "0.store"), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	topics, err = NewTopics(dir, c)
+	require.NoError(t, err)
+	defer topics.Close()
+	require.Contains(t, out.String(), "[WARN]  discarded a partially written record on recovery")
+	require.Contains(t, out.String(), "bytes=3")
+}
+
+func TestTopicsProducerExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topics-producer-expiry-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	topics, err := NewTopics(dir, Config{})
+	require.NoError(t, err)
+	defer topics.Close()
+	f := &fsm{topics: topics}
+	now := time.Now()
+	appendFrom := func(id string, sequence uint64, timestamp, appendedAt time.Time) []uint64 {
+		b, err := (&api.ProduceBatchRequest{
+			Records:    []*api.Record{{Value: []byte(id), Timestamp: timestamp.UnixNano()}},
+			ProducerId: id,
+			Sequence:   sequence,
+			AppendedAt: appendedAt.UnixNano(),
+		}).Marshal()
+		require.NoError(t, err)
+		res := f.applyAppendBatch(b)
+		require.IsType(t, &appendBatchResponse{}, res)
+		return res.(*appendBatchResponse).offsets
+	}
+
+	require.Equal(t, []uint64{0}, appendFrom("a", 1, now, now))
+	// a producer stamping its records far in the future doesn't make the others look idle
+	require.Equal(t, []uint64{1}, appendFrom("b", 1, now.Add(30*24*time.Hour), now))
+	require.Equal(t, []uint64{0}, appendFrom("a", 1, now, now))
+
+	// producers are forgotten once the leader's clock has moved on past producerExpiry
+	later := now.Add(producerExpiry + time.Hour)
+	require.Equal(t, []uint64{2}, appendFrom("c", 1, later, later))
+	require.Equal(t, []uint64{3}, appendFrom("a", 1, later, later))
+}
@@ -4,8 +4,17 @@ package log
 type RequestType uint8
 
 const (
-	AppendRequestType RequestType = 0
+	AppendRequestType       RequestType = 0
+	AppendBatchRequestType  RequestType = 1
+	CreateTopicRequestType  RequestType = 2
+	DeleteTopicRequestType  RequestType = 3
+	CommitOffsetRequestType RequestType = 4
+	AddRuleRequestType      RequestType = 5
+	RemoveRuleRequestType   RequestType = 6
 )
 
 // Identifier to identify connection type when we multiplex Raft on the same port as our log gRPC requests
 const RaftRPC = 1
+
+// Identifies a Raft connection for a partition other than the first, followed by the partition's number
+const RaftPartitionRPC = 2
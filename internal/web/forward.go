@@ -0,0 +1,136 @@
+package web
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	api "ledger/api/v1"
+)
+
+// metadata keys a forwarded request carries, with the partition it was forwarded for and the subject of the client
+// that sent it
+// The leader never forwards such a request again, so a request hops at most once, and authorizes it as the client
+// rather than the server that forwarded it
+// Both are only trusted from servers allowed to forward on the cluster, see forwardedFor
+const (
+	forwardedKey        = "ledger-forwarded-partition"
+	forwardedSubjectKey = "ledger-forwarded-subject"
+)
+
+// Forwarder sends writes that reach a follower on to the partition's leader, so clients can talk to any server
+type Forwarder struct {
+	opts  []grpc.DialOption
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// Connections to leaders are dialed with the options, e.g. the credentials servers use with each other
+func NewForwarder(opts ...grpc.DialOption) *Forwarder {
+	return &Forwarder{
+		opts:  opts,
+		conns: make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Returns a client for the leader of the partition the error names, along with a context that marks requests sent
+// with it as forwarded
+// Returns the error as is if there's no leader to forward to
+func (f *Forwarder) client(ctx context.Context, notLeader api.ErrNotLeader, partition uint32) (
+	api.LogClient,
+	context.Context,
+	error,
+) {
+	conn, ctx, err := f.conn(ctx, notLeader, partition)
+	if err != nil {
+		return nil, nil, err
+	}
+	return api.NewLogClient(conn), ctx, nil
+}
+
+// Like client, but returns the connection itself, for services other than the log's
+func (f *Forwarder) conn(ctx context.Context, notLeader api.ErrNotLeader, partition uint32) (
+	*grpc.ClientConn,
+	context.Context,
+	error,
+) {
+	if notLeader.Leader == "" {
+		return nil, nil, notLeader
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	conn, ok := f.conns[notLeader.Leader]
+	if !ok {
+		var err error
+		// dialing doesn't block, the connection's made on the first request
+		conn, err = grpc.Dial(notLeader.Leader, f.opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.conns[notLeader.Leader] = conn
+	}
+	// keep the caller's deadline and cancellation
+	md := metadata.Pairs(forwardedKey, strconv.FormatUint(uint64(partition), 10))
+	if subject, ok := ctx.Value(subjectContextKey{}).(string); ok {
+		md.Set(forwardedSubjectKey, subject)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	return conn, ctx, nil
+}
+
+// Closes the connections to leaders
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var err error
+	for addr, conn := range f.conns {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(f.conns, addr)
+	}
+	return err
+}
+
+// Returns the partition the request was forwarded for if another server forwarded it
+func forwarded(ctx context.Context) (uint32, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	v := md.Get(forwardedKey)
+	if len(v) == 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseUint(v[0], 10, 32)
+	return uint32(p), err == nil
+}
+
+// Returns the subject to authorize the request as: the client a server forwarded it for, or the peer itself
+//
+// Forwarding metadata is only trusted from peers allowed to forward on the cluster. Anyone else's is dropped, so a
+// client can't claim to act for another subject, or pin its request to a partition without it being forwarded.
+func (this *grpcServer) forwardedFor(ctx context.Context, peer string) (context.Context, string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || (len(md.Get(forwardedKey)) == 0 && len(md.Get(forwardedSubjectKey)) == 0) {
+		return ctx, peer
+	}
+	client := md.Get(forwardedSubjectKey)
+	if len(client) == 1 && this.Authorizer.Authorize(peer, clusterObject, forwardAction) == nil {
+		return ctx, client[0]
+	}
+	md = md.Copy()
+	delete(md, forwardedKey)
+	delete(md, forwardedSubjectKey)
+	return metadata.NewIncomingContext(ctx, md), peer
+}
+
+// Returns the error naming the leader if the request should be forwarded to it
+func (this *grpcServer) shouldForward(ctx context.Context, err error) (api.ErrNotLeader, bool) {
+	notLeader, ok := err.(api.ErrNotLeader)
+	if !ok || this.Forwarder == nil {
+		return notLeader, false
+	}
+	_, wasForwarded := forwarded(ctx)
+	return notLeader, !wasForwarded
+}
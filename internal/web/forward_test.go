@@ -0,0 +1,223 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	api "ledger/api/v1"
+	"ledger/config"
+	"ledger/internal/log"
+)
+
+func TestForwarding(t *testing.T) {
+	var cleanup []func()
+	defer func() {
+		for _, fn := range cleanup {
+			fn()
+		}
+	}()
+	serve := func(clog CommitLog, forwarder *Forwarder) (string, api.LogClient) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		server, err := NewGRPCServer(&Config{CommitLog: clog, Forwarder: forwarder})
+		require.NoError(t, err)
+		go server.Serve(l)
+		cleanup = append(cleanup, server.Stop)
+		conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+		require.NoError(t, err)
+		cleanup = append(cleanup, func() { conn.Close() })
+		return l.Addr().String(), api.NewLogClient(conn)
+	}
+	topics := func() log.LocalPartitions {
+		dir, err := ioutil.TempDir("", "forward-test")
+		require.NoError(t, err)
+		cleanup = append(cleanup, func() { os.RemoveAll(dir) })
+		topics, err := log.NewTopics(dir, log.Config{})
+		require.NoError(t, err)
+		return log.LocalPartitions{topics}
+	}
+
+	forwarder := NewForwarder(grpc.WithInsecure())
+	defer forwarder.Close()
+	leaderAddr, leader := serve(topics(), forwarder)
+	_, follower := serve(&followerLog{topics(), leaderAddr}, forwarder)
+	ctx := context.Background()
+
+	res, err := follower.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("forwarded")}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), res.Offset)
+
+	stream, err := follower.ProduceStream(ctx)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, stream.Send(&api.ProduceRequest{Record: &api.Record{Value: []byte("forwarded")}}))
+	}
+	for offset := uint64(1); offset < 4; offset++ {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, offset, res.Offset)
+	}
+
+	_, err = follower.CreateTopic(ctx, &api.CreateTopicRequest{Name: "payments"})
+	require.NoError(t, err)
+	topicsRes, err := leader.ListTopics(ctx, &api.ListTopicsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{log.DefaultTopic, "payments"}, topicsRes.Topics)
+
+	consume, err := leader.Consume(ctx, &api.ConsumeRequest{Offset: 3})
+	require.NoError(t, err)
+	require.Equal(t, []byte("forwarded"), consume.Record.Value)
+
+	// without a forwarder, the client's told where the leader is
+	_, lonely := serve(&followerLog{topics(), leaderAddr}, nil)
+	_, err = lonely.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("turned away")}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Equal(t, leaderAddr, notLeaderDetail(t, err))
+
+	// a forwarded request isn't forwarded again, even if the leader's moved on
+	confusedAddr, _ := serve(&followerLog{topics(), leaderAddr}, nil)
+	_, misled := serve(&followerLog{topics(), confusedAddr}, forwarder)
+	_, err = misled.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("turned away")}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Equal(t, leaderAddr, notLeaderDetail(t, err))
+}
+
+func TestForwardingAuthorization(t *testing.T) {
+	var cleanup []func()
+	defer func() {
+		for _, fn := range cleanup {
+			fn()
+		}
+	}()
+	tlsConfig := func(cert, key string, server bool) *tls.Config {
+		c, err := SetupTLSConfig(TLSConfig{CertFile: cert, KeyFile: key, CAFile: config.CAFile, Server: server})
+		require.NoError(t, err)
+		return c
+	}
+	serverCreds := grpc.Creds(credentials.NewTLS(tlsConfig(config.ServerCertFile, config.ServerKeyFile, true)))
+	// servers forward with their own certificate, like the agent's peer TLS config
+	peerCreds := grpc.WithTransportCredentials(credentials.NewTLS(
+		tlsConfig(config.ServerCertFile, config.ServerKeyFile, false),
+	))
+	dial := func(addr string, cert, key string) api.LogClient {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig(cert, key, false))))
+		require.NoError(t, err)
+		cleanup = append(cleanup, func() { conn.Close() })
+		return api.NewLogClient(conn)
+	}
+	serve := func(clog CommitLog, authorizer testAuthorizer, forwarder *Forwarder) string {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		server, err := NewGRPCServer(&Config{CommitLog: clog, Authorizer: authorizer, Forwarder: forwarder}, serverCreds)
+		require.NoError(t, err)
+		go server.Serve(l)
+		cleanup = append(cleanup, server.Stop)
+		return l.Addr().String()
+	}
+	partitions := func() log.LocalPartitions {
+		dir, err := ioutil.TempDir("", "forward-test")
+		require.NoError(t, err)
+		cleanup = append(cleanup, func() { os.RemoveAll(dir) })
+		topics, err := log.NewTopics(dir, log.Config{})
+		require.NoError(t, err)
+		return log.LocalPartitions{topics}
+	}
+	produce := func(client api.LogClient, ctx context.Context) error {
+		_, err := client.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("payment")}})
+		return err
+	}
+	ctx := context.Background()
+
+	// the subject servers forward as
+	cert, err := tls.LoadX509KeyPair(config.ServerCertFile, config.ServerKeyFile)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	authorizer := testAuthorizer{
+		"root topic/default produce":                 true,
+		leaf.Subject.CommonName + " cluster forward": true,
+	}
+	leaderAddr := serve(partitions(), authorizer, nil)
+	forwarder := NewForwarder(peerCreds)
+	defer forwarder.Close()
+	followerAddr := serve(&followerLog{partitions(), leaderAddr}, authorizer, forwarder)
+
+	// the leader authorizes a forwarded write as the client that sent it, not the server that forwarded it
+	root := dial(followerAddr, config.RootClientCertFile, config.RootClientKeyFile)
+	require.NoError(t, produce(root, ctx))
+	nobody := dial(followerAddr, config.NobodyClientCertFile, config.NobodyClientKeyFile)
+	require.Equal(t, codes.PermissionDenied, status.Code(produce(nobody, ctx)))
+
+	// a client can't claim a write was forwarded for someone else
+	nobody = dial(leaderAddr, config.NobodyClientCertFile, config.NobodyClientKeyFile)
+	spoofed := metadata.AppendToOutgoingContext(ctx, forwardedKey, "0", forwardedSubjectKey, "root")
+	require.Equal(t, codes.PermissionDenied, status.Code(produce(nobody, spoofed)))
+
+	// and a server that isn't allowed to forward only gets what it's allowed itself
+	untrusted := testAuthorizer{"root topic/default produce": true}
+	leaderAddr = serve(partitions(), untrusted, nil)
+	followerAddr = serve(&followerLog{partitions(), leaderAddr}, untrusted, forwarder)
+	root = dial(followerAddr, config.RootClientCertFile, config.RootClientKeyFile)
+	require.Equal(t, codes.PermissionDenied, status.Code(produce(root, ctx)))
+}
+
+// Allows the "<subject> <object> <action>" requests it holds
+type testAuthorizer map[string]bool
+
+func (a testAuthorizer) Authorize(subject, object, action string) error {
+	if !a[subject+" "+object+" "+action] {
+		return status.Errorf(codes.PermissionDenied, "%s not permitted to %s to %s", subject, action, object)
+	}
+	return nil
+}
+
+// Returns the leader named in the error's details
+func notLeaderDetail(t *testing.T, err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(interface{ GetMetadata() map[string]string }); ok {
+			return info.GetMetadata()["leader"]
+		}
+	}
+	t.Fatalf("no leader in %v", err)
+	return ""
+}
+
+// Partitions of a follower, which turns writes away and names the leader
+type followerLog struct {
+	log.LocalPartitions
+	leader string
+}
+
+func (l *followerLog) Partition(p uint32) (log.Partition, error) {
+	partition, err := l.LocalPartitions.Partition(p)
+	return followerPartition{partition, l.leader}, err
+}
+
+type followerPartition struct {
+	log.Partition
+	leader string
+}
+
+func (p followerPartition) Append(string, *api.Record) (uint64, error) {
+	return 0, api.ErrNotLeader{Leader: p.leader}
+}
+
+func (p followerPartition) AppendBatch(string, []*api.Record) ([]uint64, error) {
+	return nil, api.ErrNotLeader{Leader: p.leader}
+}
+
+func (p followerPartition) CreateTopic(string) error {
+	return api.ErrNotLeader{Leader: p.leader}
+}
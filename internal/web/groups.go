@@ -0,0 +1,105 @@
+package web
+
+import (
+	"context"
+
+	api "ledger/api/v1"
+	"ledger/internal/log"
+)
+
+func (this *grpcServer) CommitOffset(ctx context.Context, req *api.CommitOffsetRequest) (
+	*api.CommitOffsetResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), consumeAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partition, err := this.CommitLog.Partition(req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	err = partition.CommitOffset(req.Topic, req.Group, req.Offset)
+	if notLeader, ok := this.shouldForward(ctx, err); ok {
+		client, ctx, err := this.Forwarder.client(ctx, notLeader, req.Partition)
+		if err != nil {
+			return nil, err
+		}
+		return client.CommitOffset(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CommitOffsetResponse{}, nil
+}
+
+func (this *grpcServer) FetchOffset(ctx context.Context, req *api.FetchOffsetRequest) (
+	*api.FetchOffsetResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), consumeAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partition, err := this.CommitLog.Partition(req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := partition.CommittedOffset(req.Topic, req.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.FetchOffsetResponse{Offset: offset}, nil
+}
+
+// Reports each partition's lag as this server sees it, so a follower that's behind may report less lag than the leader
+func (this *grpcServer) GetLag(ctx context.Context, req *api.GetLagRequest) (*api.GetLagResponse, error) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), consumeAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &api.GetLagResponse{}
+	for p := uint32(0); p < this.CommitLog.Partitions(); p++ {
+		partition, err := this.CommitLog.Partition(p)
+		if err != nil {
+			return nil, err
+		}
+		end, err := partition.EndOffset(req.Topic)
+		if err != nil {
+			return nil, err
+		}
+		committed, err := partition.CommittedOffset(req.Topic, req.Group)
+		if _, ok := err.(api.ErrOffsetNotCommitted); !ok && err != nil {
+			return nil, err
+		}
+		lag := &api.PartitionLag{Partition: p, Committed: committed, End: end}
+		if end > committed {
+			lag.Lag = end - committed
+		}
+		res.Partitions = append(res.Partitions, lag)
+	}
+	return res, nil
+}
+
+// Returns where a stream should start, the group's committed offset if the request asks for it and there is one
+func startOffset(partition log.Partition, req *api.ConsumeRequest) (uint64, error) {
+	if !req.FromCommitted {
+		return req.Offset, nil
+	}
+	offset, err := partition.CommittedOffset(req.Topic, req.Group)
+	if _, ok := err.(api.ErrOffsetNotCommitted); ok {
+		return req.Offset, nil
+	}
+	return offset, err
+}
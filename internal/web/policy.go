@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "ledger/api/v1"
+)
+
+// The ACL rules the PolicyAdmin service manages
+// Each rule is led by its type, p for a policy rule or g for a role, like a line of the policy file
+type PolicyStore interface {
+	// rules added this way are replicated to every server
+	AddRule(rule []string) error
+	RemoveRule(rule []string) error
+	Rules() [][]string
+	// the rules in this server's policy file, which can only be changed by editing it
+	FileRules() [][]string
+}
+
+// Shares the log server's config and forwarding
+type policyServer struct {
+	*grpcServer
+}
+
+func (this *policyServer) ListRules(ctx context.Context, req *api.ListRulesRequest) (*api.ListRulesResponse, error) {
+	if err := this.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var rules []*api.Rule
+	for _, rule := range this.Policy.FileRules() {
+		rules = append(rules, &api.Rule{Type: rule[0], Values: rule[1:], FromFile: true})
+	}
+	for _, rule := range this.Policy.Rules() {
+		rules = append(rules, &api.Rule{Type: rule[0], Values: rule[1:]})
+	}
+
+	return &api.ListRulesResponse{Rules: rules}, nil
+}
+
+func (this *policyServer) AddRule(ctx context.Context, req *api.AddRuleRequest) (*api.AddRuleResponse, error) {
+	if err := this.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	rule, err := ruleValues(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.Policy.AddRule(rule)
+	if notLeader, ok := this.shouldForward(ctx, err); ok {
+		client, ctx, err := this.policyClient(ctx, notLeader)
+		if err != nil {
+			return nil, err
+		}
+		return client.AddRule(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.AddRuleResponse{}, nil
+}
+
+func (this *policyServer) RemoveRule(ctx context.Context, req *api.RemoveRuleRequest) (
+	*api.RemoveRuleResponse,
+	error,
+) {
+	if err := this.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	rule, err := ruleValues(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.Policy.RemoveRule(rule)
+	if notLeader, ok := this.shouldForward(ctx, err); ok {
+		client, ctx, err := this.policyClient(ctx, notLeader)
+		if err != nil {
+			return nil, err
+		}
+		return client.RemoveRule(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RemoveRuleResponse{}, nil
+}
+
+func (this *policyServer) authorizeAdmin(ctx context.Context) error {
+	if this.Authorizer == nil {
+		return nil
+	}
+	return this.Authorizer.Authorize(subject(ctx), clusterObject, adminAction)
+}
+
+// The policy is replicated by the first partition, so changes go to its leader
+func (this *policyServer) policyClient(ctx context.Context, notLeader api.ErrNotLeader) (
+	api.PolicyAdminClient,
+	context.Context,
+	error,
+) {
+	conn, ctx, err := this.Forwarder.conn(ctx, notLeader, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return api.NewPolicyAdminClient(conn), ctx, nil
+}
+
+// Returns the rule as a line of the policy file, led by its type
+func ruleValues(rule *api.Rule) ([]string, error) {
+	if rule == nil || rule.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "the rule has no type")
+	}
+	if rule.FromFile {
+		return nil, status.Error(codes.InvalidArgument, "rules from the policy file can only be changed by editing it")
+	}
+	return append([]string{rule.Type}, rule.Values...), nil
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "ledger/api/v1"
+	"ledger/config"
+	"ledger/internal/auth"
+)
+
+func TestPolicyAdmin(t *testing.T) {
+	serve := func(policy PolicyStore, forwarder *Forwarder) (string, api.PolicyAdminClient, func()) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		server, err := NewGRPCServer(&Config{Policy: policy, Forwarder: forwarder})
+		require.NoError(t, err)
+		go server.Serve(l)
+		conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+		require.NoError(t, err)
+		return l.Addr().String(), api.NewPolicyAdminClient(conn), func() {
+			conn.Close()
+			server.Stop()
+		}
+	}
+
+	file := []string{"g", "root", "admin"}
+	leaderPolicy := &testPolicy{fileRules: [][]string{file}}
+	leaderAddr, leader, stop := serve(leaderPolicy, nil)
+	defer stop()
+	forwarder := NewForwarder(grpc.WithInsecure())
+	defer forwarder.Close()
+	_, follower, stop := serve(&testPolicy{fileRules: [][]string{file}, leader: leaderAddr}, forwarder)
+	defer stop()
+	ctx := context.Background()
+
+	// changes made through a follower are forwarded to the first partition's leader
+	rule := &api.Rule{Type: "g", Values: []string{"billing", "writer"}}
+	_, err := follower.AddRule(ctx, &api.AddRuleRequest{Rule: rule})
+	require.NoError(t, err)
+	res, err := leader.ListRules(ctx, &api.ListRulesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []*api.Rule{
+		{Type: "g", Values: []string{"root", "admin"}, FromFile: true},
+		rule,
+	}, res.Rules)
+
+	_, err = follower.RemoveRule(ctx, &api.RemoveRuleRequest{Rule: rule})
+	require.NoError(t, err)
+	require.Empty(t, leaderPolicy.Rules())
+	_, err = leader.RemoveRule(ctx, &api.RemoveRuleRequest{Rule: rule})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// rules from the policy file can't be changed through the service
+	_, err = leader.RemoveRule(ctx, &api.RemoveRuleRequest{Rule: &api.Rule{Type: "g", Values: file[1:], FromFile: true}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = leader.AddRule(ctx, &api.AddRuleRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// managing the policy takes admin on the cluster
+	server := &policyServer{&grpcServer{&Config{
+		Authorizer: auth.New(config.ACLModelFile, config.ACLPolicyFile),
+		Policy:     &testPolicy{},
+	}}}
+	nobody := context.WithValue(ctx, subjectContextKey{}, "nobody")
+	_, err = server.ListRules(nobody, &api.ListRulesRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = server.AddRule(nobody, &api.AddRuleRequest{Rule: rule})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	root := context.WithValue(ctx, subjectContextKey{}, "root")
+	_, err = server.AddRule(root, &api.AddRuleRequest{Rule: rule})
+	require.NoError(t, err)
+}
+
+// Keeps the rules in memory, or turns changes away naming the leader if it has one
+type testPolicy struct {
+	fileRules [][]string
+	rules     [][]string
+	leader    string
+}
+
+func (p *testPolicy) AddRule(rule []string) error {
+	if p.leader != "" {
+		return api.ErrNotLeader{Leader: p.leader}
+	}
+	p.rules = append(p.rules, rule)
+	return nil
+}
+
+func (p *testPolicy) RemoveRule(rule []string) error {
+	if p.leader != "" {
+		return api.ErrNotLeader{Leader: p.leader}
+	}
+	for i, r := range p.rules {
+		if reflect.DeepEqual(r, rule) {
+			p.rules = append(p.rules[:i], p.rules[i+1:]...)
+			return nil
+		}
+	}
+	return api.ErrRuleNotFound{Rule: rule}
+}
+
+func (p *testPolicy) Rules() [][]string {
+	return p.rules
+}
+
+func (p *testPolicy) FileRules() [][]string {
+	return p.fileRules
+}
@@ -2,6 +2,8 @@ package web
 
 import (
 	"context"
+	"math"
+	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
@@ -12,26 +14,52 @@ import (
 	"google.golang.org/grpc/status"
 
 	api "ledger/api/v1"
+	"ledger/internal/log"
 )
 
 // ACL policy keywords
 const (
-	objectWildcard = "*"
+	// object for requests about the cluster as a whole rather than a topic
+	clusterObject  = "cluster"
 	produceAction  = "produce"
 	consumeAction  = "consume"
+	adminAction    = "admin"
+	describeAction = "describe"
+	// servers need it on the cluster to forward requests on behalf of their clients
+	forwardAction = "forward"
 )
 
+// Returns the ACL object for the topic, the default topic if the name is empty
+func topicObject(topic string) string {
+	if topic == "" {
+		topic = log.DefaultTopic
+	}
+	return "topic/" + topic
+}
+
+// most records ProduceStream appends as a single batch
+const produceBatchMax = 1024
+
+// most records a consume stream sends in a batch when the client only limits the batch's bytes
+const consumeBatchMax = 1024
+
 var _ api.LogServer = (*grpcServer)(nil)
 
 type Config struct {
 	CommitLog    CommitLog
 	Authorizer   Authorizer
 	ServerGetter ServerGetter
+	// forwards writes that reach a follower to the partition's leader, nil returns api.ErrNotLeader instead
+	Forwarder *Forwarder
+	// ACL rules managed through the PolicyAdmin service, which is only served if this is set
+	Policy PolicyStore
 }
 
+// Every topic is split into the same partitions, each partition's methods take the name of the topic they work on,
+// where an empty name means the default topic
 type CommitLog interface {
-	Append(*api.Record) (uint64, error)
-	Read(uint64) (*api.Record, error)
+	Partitions() uint32
+	Partition(partition uint32) (log.Partition, error)
 }
 
 type Authorizer interface {
@@ -47,7 +75,9 @@ type grpcServer struct {
 }
 
 func NewGRPCServer(config *Config, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	logServer := &grpcServer{config}
 	if config.Authorizer != nil {
+		identify := logServer.identify
 		opts = append(opts,
 			grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(grpc_auth.StreamServerInterceptor(identify))),
 			grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(grpc_auth.UnaryServerInterceptor(identify))),
@@ -55,37 +85,86 @@ func NewGRPCServer(config *Config, opts ...grpc.ServerOption) (*grpc.Server, err
 	}
 	server := grpc.NewServer(opts...)
 
-	logServer := &grpcServer{config}
-
 	api.RegisterLogServer(server, logServer)
+	if config.Policy != nil {
+		api.RegisterPolicyAdminServer(server, &policyServer{logServer})
+	}
 	return server, nil
 }
 
 func (this *grpcServer) Produce(ctx context.Context, req *api.ProduceRequest) (*api.ProduceResponse, error) {
 	if this.Authorizer != nil {
-		err := this.Authorizer.Authorize(subject(ctx), objectWildcard, consumeAction)
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), produceAction)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	offset, err := this.CommitLog.Append(req.Record)
+	p := this.partitionFor(req)
+	partition, err := this.CommitLog.Partition(p)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := appendRecord(partition, req)
+	if notLeader, ok := this.shouldForward(ctx, err); ok {
+		client, ctx, err := this.Forwarder.client(ctx, notLeader, p)
+		if err != nil {
+			return nil, err
+		}
+		return client.Produce(ctx, req)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &api.ProduceResponse{Offset: offset}, nil
+	return &api.ProduceResponse{Offset: offset, Partition: p}, nil
+}
+
+// Appends the request's record, through its producer's sequence numbers if it has a producer ID
+func appendRecord(partition log.Partition, req *api.ProduceRequest) (uint64, error) {
+	if req.ProducerId == "" {
+		return partition.Append(req.Topic, req.Record)
+	}
+	offsets, err := appendBatch(partition, req, []*api.Record{req.Record})
+	if err != nil {
+		return 0, err
+	}
+	return offsets[0], nil
+}
+
+// Appends the records of a batch that starts with the request
+func appendBatch(partition log.Partition, req *api.ProduceRequest, records []*api.Record) ([]uint64, error) {
+	if req.ProducerId == "" {
+		return partition.AppendBatch(req.Topic, records)
+	}
+	producer := log.Producer{ID: req.ProducerId, Sequence: req.Sequence}
+	return partition.AppendFrom(req.Topic, producer, records)
+}
+
+// Returns the partition to append the request's record to, records with a key always go to the key's partition
+func (this *grpcServer) partitionFor(req *api.ProduceRequest) uint32 {
+	if req.Record != nil && len(req.Record.Key) > 0 {
+		return log.PartitionFor(req.Record.Key, this.CommitLog.Partitions())
+	}
+	return req.Partition
 }
 
 func (this *grpcServer) Consume(ctx context.Context, req *api.ConsumeRequest) (*api.ConsumeResponse, error) {
 	if this.Authorizer != nil {
-		err := this.Authorizer.Authorize(subject(ctx), objectWildcard, consumeAction)
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), consumeAction)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	record, err := this.CommitLog.Read(req.Offset)
+	partition, err := this.CommitLog.Partition(req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	if err = partition.WaitForConsistency(req.Consistency); err != nil {
+		return nil, err
+	}
+	record, err := partition.Read(req.Topic, req.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -95,68 +174,461 @@ func (this *grpcServer) Consume(ctx context.Context, req *api.ConsumeRequest) (*
 	}, nil
 }
 
+// Records that arrive while the previous batch is being appended are appended together as the next batch, so a client
+// that sends without waiting for each response only pays for one append per batch
+// A batch only holds records for the same topic and partition, so switching either starts a new batch
+// It only holds consecutive records from the same producer too, so the batch's sequence numbers have no gaps
+// Each request may be for a different topic, so the client's authorized for each topic the first time it produces to it
 func (this *grpcServer) ProduceStream(stream api.Log_ProduceStreamServer) error {
+	// receive in the background so requests queue up while we append
+	reqs := make(chan *api.ProduceRequest, produceBatchMax)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(reqs)
+		for {
+			// get an incoming
+			req, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-stream.Context().Done():
+				errc <- stream.Context().Err()
+				return
+			}
+		}
+	}()
+
+	// streams batches are forwarded on when this server isn't the partition's leader, by leader
+	forwarded := make(map[string]api.Log_ProduceStreamClient)
+	defer func() {
+		for _, s := range forwarded {
+			_ = s.CloseSend()
+		}
+	}()
+
+	// topics the client's been authorized to produce to
+	authorized := make(map[string]bool)
+	// the request that ended the last batch by switching topics, partitions or producers, it starts the next one
+	var next *api.ProduceRequest
 	for {
-		// get an incoming
-		req, err := stream.Recv()
+		req := next
+		next = nil
+		if req == nil {
+			var ok bool
+			if req, ok = <-reqs; !ok {
+				return <-errc
+			}
+		}
+		// a batch only holds records for the topic its first request is for
+		if this.Authorizer != nil && !authorized[req.Topic] {
+			err := this.Authorizer.Authorize(subject(stream.Context()), topicObject(req.Topic), produceAction)
+			if err != nil {
+				return err
+			}
+			authorized[req.Topic] = true
+		}
+		p := this.partitionFor(req)
+		records := []*api.Record{req.Record}
+	batch:
+		for len(records) < produceBatchMax {
+			select {
+			case r, ok := <-reqs:
+				if !ok {
+					break batch
+				}
+				if r.Topic != req.Topic || this.partitionFor(r) != p ||
+					r.ProducerId != req.ProducerId ||
+					(r.ProducerId != "" && r.Sequence != req.Sequence+uint64(len(records))) {
+					next = r
+					break batch
+				}
+				records = append(records, r.Record)
+			default:
+				break batch
+			}
+		}
+
+		partition, err := this.CommitLog.Partition(p)
 		if err != nil {
 			return err
 		}
-
-		res, err := this.Produce(stream.Context(), req)
+		offsets, err := appendBatch(partition, req, records)
+		if notLeader, ok := this.shouldForward(stream.Context(), err); ok {
+			err = this.forwardBatch(stream, forwarded, notLeader, req, p, records)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if err != nil {
 			return err
 		}
+		for _, offset := range offsets {
+			err = stream.Send(&api.ProduceResponse{Offset: offset, Partition: p})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
 
-		err = stream.Send(res)
+// Sends the batch that starts with the request on to the partition's leader and its responses back to the client
+// The batch is sent as separate requests, so the leader may append it in more than one batch
+func (this *grpcServer) forwardBatch(
+	stream api.Log_ProduceStreamServer,
+	forwarded map[string]api.Log_ProduceStreamClient,
+	notLeader api.ErrNotLeader,
+	first *api.ProduceRequest,
+	partition uint32,
+	records []*api.Record,
+) error {
+	leader, ok := forwarded[notLeader.Leader]
+	if !ok {
+		client, ctx, err := this.Forwarder.client(stream.Context(), notLeader, partition)
+		if err != nil {
+			return err
+		}
+		if leader, err = client.ProduceStream(ctx); err != nil {
+			return err
+		}
+		forwarded[notLeader.Leader] = leader
+	}
+	for i, record := range records {
+		req := &api.ProduceRequest{Topic: first.Topic, Partition: partition, Record: record}
+		if first.ProducerId != "" {
+			req.ProducerId, req.Sequence = first.ProducerId, first.Sequence+uint64(i)
+		}
+		err := leader.Send(req)
+		if err != nil {
+			return err
+		}
+	}
+	for range records {
+		res, err := leader.Recv()
 		if err != nil {
 			return err
 		}
+		if err = stream.Send(res); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func (this *grpcServer) ConsumeStream(req *api.ConsumeRequest, stream api.Log_ConsumeStreamServer) error {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(stream.Context()), topicObject(req.Topic), consumeAction)
+		if err != nil {
+			return err
+		}
+	}
+	return this.streamRecords(stream.Context(), req, nil, stream.Send)
+}
+
+func (this *grpcServer) ConsumeWindowed(stream api.Log_ConsumeWindowedServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.Start == nil {
+		return status.Error(codes.InvalidArgument, "the first request must say where to start consuming")
+	}
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(stream.Context()), topicObject(first.Start.Topic), consumeAction)
+		if err != nil {
+			return err
+		}
+	}
+	// receive credit in the background so it's granted while we wait for records
+	credits := make(chan uint32, 16)
+	credits <- first.Credit
+	go func() {
+		defer close(credits)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case credits <- req.Credit:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+	return this.streamRecords(stream.Context(), first.Start, credits, stream.Send)
+}
+
+// Streams every record from where the request starts on, and once caught up waits for the next record to be appended
+// With credits, only sends as many records as the client has granted, a nil channel grants unlimited credit
+func (this *grpcServer) streamRecords(
+	ctx context.Context,
+	req *api.ConsumeRequest,
+	credits <-chan uint32,
+	send func(*api.ConsumeResponse) error,
+) error {
+	partition, err := this.CommitLog.Partition(req.Partition)
+	if err != nil {
+		return err
+	}
+	// the consistency level applies to where the stream starts, the records after it are streamed as they're applied
+	if err = partition.WaitForConsistency(req.Consistency); err != nil {
+		return err
+	}
+	offset, err := startOffset(partition, req)
+	if err != nil {
+		return err
+	}
+	records, err := partition.Iterator(req.Topic, offset, math.MaxUint64, 0)
+	if err != nil {
+		return err
+	}
+
+	batched := req.MaxRecords > 0 || req.MaxBytes > 0
+	limit := uint64(1)
+	if batched {
+		limit = consumeBatchMax
+		if req.MaxRecords > 0 {
+			limit = uint64(req.MaxRecords)
+		}
+	}
+	credit := uint64(math.MaxUint64)
+	if credits != nil {
+		credit = 0
+	}
+	// read last time round but over the byte limit, so it starts the next batch
+	var held *api.Record
 	for {
-		select {
-		case <-stream.Context().Done():
-			return nil
-
-		// start reading logs starting at req.Offset
-		// will stream every record that follows
-		// when there are no more logs to read, the server will wait til another record is appended
-		default:
-			res, err := this.Consume(stream.Context(), req)
-			switch err.(type) {
-			case nil:
-			case api.ErrOffsetOutOfRange:
-				continue
-			default:
-				return err
+		for credit == 0 {
+			if credits == nil {
+				// out of credit and the client's done granting more
+				return nil
 			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case c, ok := <-credits:
+				if !ok {
+					credits = nil
+				}
+				credit += uint64(c)
+			}
+		}
 
-			err = stream.Send(res)
-			if err != nil {
-				return err
+		n := limit
+		if credit < n {
+			n = credit
+		}
+		var batch []*api.Record
+		var size uint64
+		for uint64(len(batch)) < n {
+			record := held
+			held = nil
+			if record == nil {
+				if !records.Next() {
+					break
+				}
+				record = records.Record()
+			}
+			if req.MaxBytes > 0 && len(batch) > 0 && size+uint64(record.Size()) > req.MaxBytes {
+				held = record
+				break
 			}
+			size += uint64(record.Size())
+			batch = append(batch, record)
+		}
+		if err := records.Err(); err != nil {
+			return err
+		}
+
+		if len(batch) == 0 {
+			// caught up, wait for the next record, taking any credit granted meanwhile
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-records.Wait():
+			case c, ok := <-credits:
+				if !ok {
+					credits = nil
+				}
+				credit += uint64(c)
+			}
+			continue
+		}
+		credit -= uint64(len(batch))
+		res := &api.ConsumeResponse{Records: batch}
+		if !batched {
+			res = &api.ConsumeResponse{Record: batch[0]}
+		}
+		if err := send(res); err != nil {
+			return err
+		}
+	}
+}
+
+func (this *grpcServer) OffsetForTime(ctx context.Context, req *api.OffsetForTimeRequest) (
+	*api.OffsetForTimeResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Topic), consumeAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partition, err := this.CommitLog.Partition(req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := partition.OffsetForTime(req.Topic, time.Unix(0, req.Timestamp))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.OffsetForTimeResponse{Offset: offset}, nil
+}
+
+func (this *grpcServer) CreateTopic(ctx context.Context, req *api.CreateTopicRequest) (
+	*api.CreateTopicResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Name), adminAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// every partition gets the topic, a retry after some partitions failed creates it in the rest
+	err := this.eachPartition(ctx, func(partition log.Partition) error {
+		return partition.CreateTopic(req.Name)
+	}, func(ctx context.Context, leader api.LogClient) error {
+		_, err := leader.CreateTopic(ctx, req)
+		return err
+	}, api.ErrTopicExists{Topic: req.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateTopicResponse{}, nil
+}
+
+func (this *grpcServer) DeleteTopic(ctx context.Context, req *api.DeleteTopicRequest) (
+	*api.DeleteTopicResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), topicObject(req.Name), adminAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := this.eachPartition(ctx, func(partition log.Partition) error {
+		return partition.DeleteTopic(req.Name)
+	}, func(ctx context.Context, leader api.LogClient) error {
+		_, err := leader.DeleteTopic(ctx, req)
+		return err
+	}, api.ErrTopicNotFound{Topic: req.Name})
+	if err != nil {
+		return nil, err
+	}
 
-			req.Offset += 1
+	return &api.DeleteTopicResponse{}, nil
+}
+
+func (this *grpcServer) ListTopics(ctx context.Context, req *api.ListTopicsRequest) (
+	*api.ListTopicsResponse,
+	error,
+) {
+	if this.Authorizer != nil {
+		err := this.Authorizer.Authorize(subject(ctx), clusterObject, describeAction)
+		if err != nil {
+			return nil, err
 		}
+	}
 
+	// every partition has the same topics
+	partition, err := this.CommitLog.Partition(0)
+	if err != nil {
+		return nil, err
+	}
+	topics, err := partition.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ListTopicsResponse{Topics: topics}, nil
+}
+
+// Calls fn with every partition, or forward with the leader of each partition this server doesn't lead, ignoring
+// errors like done unless every partition returns one, so the call can be retried when only some partitions failed
+// A request forwarded by another server is only for the partition it was forwarded for
+func (this *grpcServer) eachPartition(
+	ctx context.Context,
+	fn func(log.Partition) error,
+	forward func(context.Context, api.LogClient) error,
+	done error,
+) error {
+	first, last := uint32(0), this.CommitLog.Partitions()
+	if p, ok := forwarded(ctx); ok {
+		first, last = p, p+1
+	}
+	var skipped uint32
+	for p := first; p < last; p++ {
+		partition, err := this.CommitLog.Partition(p)
+		if err != nil {
+			return err
+		}
+		err = fn(partition)
+		if notLeader, ok := this.shouldForward(ctx, err); ok {
+			var leader api.LogClient
+			var fctx context.Context
+			if leader, fctx, err = this.Forwarder.client(ctx, notLeader, p); err == nil {
+				err = forward(fctx, leader)
+			}
+		}
+		// errors from the leader come back as statuses, so compare codes
+		if err != nil && status.Code(err) == status.Code(done) {
+			skipped++
+		} else if err != nil {
+			return err
+		}
+	}
+	if skipped == last-first {
+		return done
 	}
+	return nil
 }
 
 func (s *grpcServer) GetServers(ctx context.Context, req *api.GetServersRequest) (*api.GetServersResponse, error) {
+	if s.Authorizer != nil {
+		err := s.Authorizer.Authorize(subject(ctx), clusterObject, describeAction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	servers, err := s.ServerGetter.GetServers()
 	if err != nil {
 		return nil, err
 	}
 
-	return &api.GetServersResponse{Servers: servers}, nil
+	res := &api.GetServersResponse{Servers: servers, Partitions: 1}
+	if s.CommitLog != nil {
+		res.Partitions = s.CommitLog.Partitions()
+	}
+	return res, nil
 }
 
 // Identify the subject to enable authorization
-// Interceptor/middleware reads subject out of the client's cert and writes it to the RPC's context
-func identify(ctx context.Context) (context.Context, error) {
+// Interceptor/middleware reads subject out of the client's cert and writes it to the RPC's context, or the subject of
+// the client a request was forwarded for
+func (this *grpcServer) identify(ctx context.Context) (context.Context, error) {
 	peer, ok := peer.FromContext(ctx)
 	if !ok {
 		return ctx, status.New(codes.Unknown, "couldn't find peer info").Err()
@@ -168,6 +640,7 @@ func identify(ctx context.Context) (context.Context, error) {
 
 	tlsInfo := peer.AuthInfo.(credentials.TLSInfo)
 	subject := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	ctx, subject = this.forwardedFor(ctx, subject)
 	ctx = context.WithValue(ctx, subjectContextKey{}, subject)
 
 	return ctx, nil
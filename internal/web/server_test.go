@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -15,6 +16,7 @@ import (
 	api "ledger/api/v1"
 	"ledger/config"
 	"ledger/internal/auth"
+	"ledger/internal/log"
 )
 
 func TestServer(t *testing.T) {
@@ -26,8 +28,17 @@ func TestServer(t *testing.T) {
 	){
 		"success: produce/consume a message to/from the log": testProduceConsume,
 		"success: produce/consume stream":                    testProduceConsumeStream,
+		"success: produce stream batches pipelined records":  testProduceStreamBatch,
 		"fail: consume past log boundary":                    testConsumePastBoundary,
 		"unauthorized fails":                                 testUnauthorized,
+		"success: offset for time":                           testOffsetForTime,
+		"success: produce/consume to/from topics":            testTopics,
+		"success: records with a key go to its partition":    testPartitions,
+		"success: producer retries aren't appended twice":    testIdempotentProduce,
+		"success: producers number each partition's records": testIdempotentProducePartitions,
+		"success: consume stream in batches":                 testConsumeStreamBatches,
+		"success: windowed consume waits for credit":         testConsumeWindowed,
+		"success: consumer groups commit offsets":            testConsumerGroups,
 	}
 	for description, fn := range cases {
 		t.Run(description, func(t *testing.T) {
@@ -50,7 +61,7 @@ func testSetup(t *testing.T, fn func(*Config)) (
 	require.NoError(t, err)
 
 	newClient := func(crtPath, keyPath string) (*grpc.ClientConn, api.LogClient, []grpc.DialOption) {
-		tlsConfig, err := config.SetupTLSConfig(config.TLSConfig{
+		tlsConfig, err := SetupTLSConfig(TLSConfig{
 			CertFile: crtPath,
 			KeyFile:  keyPath,
 			CAFile:   config.CAFile,
@@ -79,7 +90,7 @@ func testSetup(t *testing.T, fn func(*Config)) (
 		config.NobodyClientKeyFile,
 	)
 
-	serverTLSConfig, err := config.SetupTLSConfig(config.TLSConfig{
+	serverTLSConfig, err := SetupTLSConfig(TLSConfig{
 		CertFile:      config.ServerCertFile,
 		KeyFile:       config.ServerKeyFile,
 		CAFile:        config.CAFile,
@@ -89,11 +100,15 @@ func testSetup(t *testing.T, fn func(*Config)) (
 	require.NoError(t, err)
 	serverCreds := credentials.NewTLS(serverTLSConfig)
 
-	dir, err := ioutil.TempDir("", "server-test")
-	require.NoError(t, err)
-
-	clog, err := log_2.NewLog(dir, log_2.Config{})
-	require.NoError(t, err)
+	// two partitions, so routing records by key is covered too
+	var clog log.LocalPartitions
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "server-test")
+		require.NoError(t, err)
+		topics, err := log.NewTopics(dir, log.Config{})
+		require.NoError(t, err)
+		clog = append(clog, topics)
+	}
 
 	authorizer := auth.New(config.ACLModelFile, config.ACLPolicyFile)
 	cfg = &Config{
@@ -141,8 +156,8 @@ func testConsumePastBoundary(t *testing.T, client, _ api.LogClient, config *Conf
 func testProduceConsumeStream(t *testing.T, client, _ api.LogClient, config *Config) {
 	ctx := context.Background()
 	records := []*api.Record{
-		{Value: []byte("first msg")},
-		{Value: []byte("second msg")},
+		{Value: []byte("first msg"), Timestamp: 1},
+		{Value: []byte("second msg"), Timestamp: 2},
 	}
 	{ // append records
 		stream, err := client.ProduceStream(ctx)
@@ -163,20 +178,49 @@ func testProduceConsumeStream(t *testing.T, client, _ api.LogClient, config *Con
 		stream, err := client.ConsumeStream(ctx, &api.ConsumeRequest{Offset: 0})
 		require.NoError(t, err)
 
-		for _, record := range records {
+		for i, record := range records {
 			res, err := stream.Recv()
 			require.NoError(t, err)
-			require.Equal(t, res.Record, record)
+			require.Equal(t, res.Record, &api.Record{
+				Value:     record.Value,
+				Offset:    uint64(i),
+				Timestamp: record.Timestamp,
+			})
 		}
+
+		// once caught up, the stream waits for the next record
+		produce, err := client.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("third msg")}})
+		require.NoError(t, err)
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, produce.Offset, res.Record.Offset)
 	}
+}
+
+func testProduceStreamBatch(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	stream, err := client.ProduceStream(ctx)
+	require.NoError(t, err)
 
+	// send every record before reading any responses, so they're appended in batches
+	const n = 100
+	for i := 0; i < n; i++ {
+		err = stream.Send(&api.ProduceRequest{Record: &api.Record{Value: []byte("hello")}})
+		require.NoError(t, err)
+	}
+	for offset := 0; offset < n; offset++ {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.EqualValues(t, offset, res.Offset)
+	}
 }
 
 func testProduceConsume(t *testing.T, client, _ api.LogClient, config *Config) {
 	ctx := context.Background()
 
 	want := &api.Record{
-		Value: []byte("hello world"),
+		Value:     []byte("hello world"),
+		Timestamp: time.Now().UnixNano(),
 	}
 
 	produce, err := client.Produce(
@@ -188,12 +232,33 @@ func testProduceConsume(t *testing.T, client, _ api.LogClient, config *Config) {
 	require.NoError(t, err)
 
 	consume, err := client.Consume(ctx, &api.ConsumeRequest{
-		Offset: produce.Offset,
+		Offset:      produce.Offset,
+		Consistency: api.Consistency_LINEARIZABLE,
 	})
 	require.NoError(t, err)
 	require.Equal(t, want, consume.Record)
 }
 
+func testOffsetForTime(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.Produce(ctx, &api.ProduceRequest{
+			Record: &api.Record{
+				Value:     []byte("hello world"),
+				Timestamp: start.Add(time.Duration(i) * time.Hour).UnixNano(),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	res, err := client.OffsetForTime(ctx, &api.OffsetForTimeRequest{
+		Timestamp: start.Add(30 * time.Minute).UnixNano(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Offset)
+}
+
 // {client} should be unauthorized
 func testUnauthorized(t *testing.T, _, client api.LogClient, config *Config) {
 	ctx := context.Background()
@@ -212,4 +277,266 @@ func testUnauthorized(t *testing.T, _, client api.LogClient, config *Config) {
 	})
 	require.Nil(t, consume)
 	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// create topic request
+	create, err := client.CreateTopic(ctx, &api.CreateTopicRequest{Name: "payments"})
+	require.Nil(t, create)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// get servers request
+	servers, err := client.GetServers(ctx, &api.GetServersRequest{})
+	require.Nil(t, servers)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// produce stream, authorized for the topic of the first record sent to it
+	stream, err := client.ProduceStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&api.ProduceRequest{Topic: "payments", Record: &api.Record{Value: []byte("hello")}}))
+	_, err = stream.Recv()
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func testTopics(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	_, err := client.CreateTopic(ctx, &api.CreateTopicRequest{Name: "payments"})
+	require.NoError(t, err)
+	_, err = client.CreateTopic(ctx, &api.CreateTopicRequest{Name: "payments"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// consecutive records for different topics are appended to their own topics
+	stream, err := client.ProduceStream(ctx)
+	require.NoError(t, err)
+	for _, topic := range []string{"", "payments", "payments", ""} {
+		err = stream.Send(&api.ProduceRequest{Topic: topic, Record: &api.Record{Value: []byte(topic)}})
+		require.NoError(t, err)
+	}
+	for _, want := range []uint64{0, 0, 1, 1} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, want, res.Offset)
+	}
+	consume, err := client.Consume(ctx, &api.ConsumeRequest{Topic: "payments", Offset: 1})
+	require.NoError(t, err)
+	require.Equal(t, []byte("payments"), consume.Record.Value)
+
+	list, err := client.ListTopics(ctx, &api.ListTopicsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{log.DefaultTopic, "payments"}, list.Topics)
+
+	_, err = client.DeleteTopic(ctx, &api.DeleteTopicRequest{Name: "payments"})
+	require.NoError(t, err)
+	_, err = client.Consume(ctx, &api.ConsumeRequest{Topic: "payments", Offset: 0})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func testPartitions(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	key := []byte("account-1")
+	want := log.PartitionFor(key, 2)
+	for i := uint64(0); i < 3; i++ {
+		// the key's partition wins over the one asked for
+		res, err := client.Produce(ctx, &api.ProduceRequest{
+			Partition: 1 - want,
+			Record:    &api.Record{Key: key, Value: []byte("payment")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, want, res.Partition)
+		require.Equal(t, i, res.Offset)
+	}
+	res, err := client.Produce(ctx, &api.ProduceRequest{
+		Partition: 1 - want,
+		Record:    &api.Record{Value: []byte("no key")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1-want, res.Partition)
+	require.Equal(t, uint64(0), res.Offset)
+
+	consume, err := client.Consume(ctx, &api.ConsumeRequest{Partition: want, Offset: 2})
+	require.NoError(t, err)
+	require.Equal(t, key, consume.Record.Key)
+	_, err = client.Consume(ctx, &api.ConsumeRequest{Partition: 2})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func testIdempotentProduce(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	stream, err := client.ProduceStream(ctx)
+	require.NoError(t, err)
+	// the stream batches the records, then the retry overlaps the batch
+	for _, seq := range []uint64{0, 1, 2, 1, 2, 3} {
+		err = stream.Send(&api.ProduceRequest{
+			Record:     &api.Record{Value: []byte("payment")},
+			ProducerId: "checkout",
+			Sequence:   seq,
+		})
+		require.NoError(t, err)
+	}
+	for _, want := range []uint64{0, 1, 2, 1, 2, 3} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, want, res.Offset)
+	}
+
+	res, err := client.Produce(ctx, &api.ProduceRequest{
+		Record:     &api.Record{Value: []byte("payment")},
+		ProducerId: "checkout",
+		Sequence:   3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Offset)
+	_, err = client.Consume(ctx, &api.ConsumeRequest{Offset: 4})
+	require.Error(t, err)
+
+	_, err = client.Produce(ctx, &api.ProduceRequest{
+		Record:     &api.Record{Value: []byte("payment")},
+		ProducerId: "checkout",
+		Sequence:   5,
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func testIdempotentProducePartitions(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	produce := func(partition uint32, seq uint64) (*api.ProduceResponse, error) {
+		return client.Produce(ctx, &api.ProduceRequest{
+			Record:     &api.Record{Value: []byte("payment")},
+			Partition:  partition,
+			ProducerId: "checkout",
+			Sequence:   seq,
+		})
+	}
+	// the producer keeps a sequence for each partition
+	for _, req := range []struct {
+		partition uint32
+		seq       uint64
+		offset    uint64
+	}{
+		{0, 0, 0},
+		{1, 0, 0},
+		{0, 1, 1},
+		{1, 1, 1},
+		// retries
+		{1, 0, 0},
+		{0, 1, 1},
+		{0, 2, 2},
+	} {
+		res, err := produce(req.partition, req.seq)
+		require.NoError(t, err)
+		require.Equal(t, req.partition, res.Partition)
+		require.Equal(t, req.offset, res.Offset)
+	}
+	_, err := client.Consume(ctx, &api.ConsumeRequest{Partition: 1, Offset: 2})
+	require.Error(t, err)
+
+	// carrying on one partition's sequence in the other leaves a gap
+	_, err = produce(1, 3)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func testConsumeStreamBatches(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, err := client.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("payment")}})
+		require.NoError(t, err)
+	}
+	size := uint64((&api.Record{Value: []byte("payment"), Offset: 9, Timestamp: time.Now().UnixNano()}).Size())
+
+	stream, err := client.ConsumeStream(ctx, &api.ConsumeRequest{Offset: 1, MaxRecords: 4})
+	require.NoError(t, err)
+	for _, want := range [][]uint64{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Nil(t, res.Record)
+		var offsets []uint64
+		for _, record := range res.Records {
+			offsets = append(offsets, record.Offset)
+		}
+		require.Equal(t, want, offsets)
+	}
+
+	// the byte limit holds back the records that don't fit for the next batch
+	stream, err = client.ConsumeStream(ctx, &api.ConsumeRequest{Offset: 0, MaxBytes: 3 * size})
+	require.NoError(t, err)
+	for _, want := range []int{3, 3, 3, 1} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Len(t, res.Records, want)
+	}
+}
+
+func testConsumeWindowed(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, err := client.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("payment")}})
+		require.NoError(t, err)
+	}
+
+	stream, err := client.ConsumeWindowed(ctx)
+	require.NoError(t, err)
+	err = stream.Send(&api.ConsumeWindowRequest{
+		Start:  &api.ConsumeRequest{Offset: 0, MaxRecords: 2},
+		Credit: 3,
+	})
+	require.NoError(t, err)
+	for _, want := range []int{2, 1} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Len(t, res.Records, want)
+	}
+
+	// out of credit, the server waits until the client grants more
+	received := make(chan *api.ConsumeResponse)
+	go func() {
+		res, err := stream.Recv()
+		if err == nil {
+			received <- res
+		}
+		close(received)
+	}()
+	select {
+	case <-received:
+		t.Fatal("received records without credit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, stream.Send(&api.ConsumeWindowRequest{Credit: 10}))
+	res := <-received
+	require.NotNil(t, res)
+	require.Equal(t, uint64(3), res.Records[0].Offset)
+	require.Len(t, res.Records, 2)
+}
+
+func testConsumerGroups(t *testing.T, client, _ api.LogClient, config *Config) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, err := client.Produce(ctx, &api.ProduceRequest{Record: &api.Record{Value: []byte("payment")}})
+		require.NoError(t, err)
+	}
+
+	_, err := client.FetchOffset(ctx, &api.FetchOffsetRequest{Group: "projection"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.CommitOffset(ctx, &api.CommitOffsetRequest{Group: "projection", Offset: 3})
+	require.NoError(t, err)
+	fetch, err := client.FetchOffset(ctx, &api.FetchOffsetRequest{Group: "projection"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), fetch.Offset)
+
+	lag, err := client.GetLag(ctx, &api.GetLagRequest{Group: "projection"})
+	require.NoError(t, err)
+	require.Equal(t, []*api.PartitionLag{
+		{Partition: 0, Committed: 3, End: 5, Lag: 2},
+		{Partition: 1, Committed: 0, End: 0, Lag: 0},
+	}, lag.Partitions)
+
+	stream, err := client.ConsumeStream(ctx, &api.ConsumeRequest{Group: "projection", FromCommitted: true})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.Record.Offset)
+
+	// a group that hasn't committed starts from the request's offset
+	stream, err = client.ConsumeStream(ctx, &api.ConsumeRequest{Group: "audit", FromCommitted: true, Offset: 1})
+	require.NoError(t, err)
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Record.Offset)
 }
@@ -69,12 +69,12 @@ func (s *Suite) SetupSuite() {
 	dir, err := ioutil.TempDir("", "app-test")
 	s.NoError(err)
 
-	commitLog, err := log.NewLog(dir, log.Config{})
+	commitLog, err := log.NewTopics(dir, log.Config{})
 	s.NoError(err)
 
 	// create server and client for `log`
 	logServer, err := web.NewGRPCServer(&web.Config{
-		CommitLog:    commitLog,
+		CommitLog:    log.LocalPartitions{commitLog},
 		ServerGetter: nil,
 	})
 	s.NoError(err)